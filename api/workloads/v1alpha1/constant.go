@@ -28,6 +28,28 @@ const (
 
 	// SetRBGIndexLabelKey SetRBGIndex identifies the index of the rbg within the rbgset
 	SetRBGIndexLabelKey = RBGSetPrefix + "rbg-index"
+
+	// SetRBGRevisionLabelKey identifies the revision of the rbgset a rbg was last updated to
+	// Value: hash of the template, overrides, topology spread and pod group policy of the rbgset
+	SetRBGRevisionLabelKey = RBGSetPrefix + "revision"
+
+	// SetRBGSpecHashLabelKey identifies the spec of its index a rbg of a rbgset was last updated to
+	// Value: hash of the template of the rbgset with the overrides and topology spread of the index applied
+	SetRBGSpecHashLabelKey = RBGSetPrefix + "spec-hash"
+
+	// DeletionCostAnnotationKey is set on a rbg of a rbgset by users. The rbgs with a lower cost are
+	// preferred to be removed when the rbgset is scaled down.
+	// Value: int32, 0 if not set
+	DeletionCostAnnotationKey = RBGSetPrefix + "deletion-cost"
+
+	// DrainingAnnotationKey marks a rbg which is drained before it is deleted by scale-down
+	// Value: RFC3339 time when the drain started
+	DrainingAnnotationKey = RBGSetPrefix + "draining-since"
+
+	// RecreatingInstanceAnnotationKey marks the pods deleted by rbg controller to recreate a role instance
+	// or to recover from a node failure
+	// Value: name of the pod which triggered the recreation
+	RecreatingInstanceAnnotationKey = RBGPrefix + "recreating-instance"
 )
 
 type RolloutStrategyType string
@@ -39,6 +61,56 @@ const (
 	RollingUpdateStrategyType RolloutStrategyType = "RollingUpdate"
 )
 
+type RoleServiceType string
+
+const (
+	// HeadlessServiceType creates a headless Service resolving to the addresses of all pods of the role,
+	// including the not ready ones.
+	HeadlessServiceType RoleServiceType = "Headless"
+
+	// ClusterIPServiceType, NodePortServiceType and LoadBalancerServiceType create a load balanced Service
+	// of the corresponding corev1.ServiceType.
+	ClusterIPServiceType    RoleServiceType = "ClusterIP"
+	NodePortServiceType     RoleServiceType = "NodePort"
+	LoadBalancerServiceType RoleServiceType = "LoadBalancer"
+)
+
+type DiscoverySource string
+
+const (
+	// StaticDiscoverySource synthesizes the addresses of all desired replicas of the roles.
+	StaticDiscoverySource DiscoverySource = "Static"
+
+	// PodsDiscoverySource lists the addresses of the observed pods of the roles.
+	PodsDiscoverySource DiscoverySource = "Pods"
+)
+
+type DiscoveryAddressType string
+
+const (
+	IPDiscoveryAddressType  DiscoveryAddressType = "IP"
+	DNSDiscoveryAddressType DiscoveryAddressType = "DNS"
+)
+
+type DiscoveryFormat string
+
+const (
+	YAMLDiscoveryFormat     DiscoveryFormat = "YAML"
+	JSONDiscoveryFormat     DiscoveryFormat = "JSON"
+	EnvDiscoveryFormat      DiscoveryFormat = "Env"
+	TemplateDiscoveryFormat DiscoveryFormat = "Template"
+)
+
+type EnvConflictPolicy string
+
+const (
+	// PreserveEnvConflictPolicy keeps the env vars defined by the user.
+	PreserveEnvConflictPolicy EnvConflictPolicy = "Preserve"
+
+	// OverrideEnvConflictPolicy replaces the env vars defined by the user with the injected ones.
+	OverrideEnvConflictPolicy EnvConflictPolicy = "Override"
+)
+
 type RestartPolicyType string
 
 const (
@@ -55,7 +127,17 @@ const (
 	// RecreateRoleInstanceOnPodRestart will recreate an instance of role. If role's workload is lws, it means when a pod
 	// failed, we will recreate only one lws instance, not all lws instances.
 	// It equals to RecreateGroupOnPodRestart in lws.spec.LeaderWorkerTemplate.RestartPolicyType
+	// If role's workload is sts, an instance is a block of role.instanceSize consecutive pod indices. If role's
+	// workload is deployment, an instance is a single pod.
 	RecreateRoleInstanceOnPodRestart RestartPolicyType = "RecreateRoleInstanceOnPodRestart"
+
+	// RecreateDependentRolesOnPodRestart will recreate the failed role together with all the roles which
+	// depend on it directly or transitively. Roles outside the dependency chain are not impacted.
+	RecreateDependentRolesOnPodRestart RestartPolicyType = "RecreateDependentRolesOnPodRestart"
+
+	// RecreateDirectDependentRolesOnPodRestart will recreate the failed role together with the roles which
+	// declare it in their dependencies.
+	RecreateDirectDependentRolesOnPodRestart RestartPolicyType = "RecreateDirectDependentRolesOnPodRestart"
 )
 
 const (
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"errors"
 	"fmt"
+	"slices"
 )
 
 func (rbg *RoleBasedGroup) GetCommonLabelsFromRole(role *RoleSpec) map[string]string {
@@ -36,6 +37,120 @@ func (rbg *RoleBasedGroup) GetWorkloadName(role *RoleSpec) string {
 	return fmt.Sprintf("%s-%s", rbg.Name, role.Name)
 }
 
+// GetServiceName returns the name of the Service of role, the headless Service shares the name of the workload.
+func (rbg *RoleBasedGroup) GetServiceName(role *RoleSpec) string {
+	if role.Service == nil || role.Service.Type == "" || role.Service.Type == HeadlessServiceType {
+		return rbg.GetWorkloadName(role)
+	}
+	return fmt.Sprintf("%s-svc", rbg.GetWorkloadName(role))
+}
+
+// DiscoverPods reports whether the discovery config of rbg lists the observed pods of the roles.
+func (rbg *RoleBasedGroup) DiscoverPods() bool {
+	return rbg.Spec.Discovery != nil && rbg.Spec.Discovery.Source == PodsDiscoverySource
+}
+
+// GetDiscoveryFormat returns the format of the discovery config of the role.
+func (role *RoleSpec) GetDiscoveryFormat() DiscoveryFormat {
+	if role.Discovery == nil || role.Discovery.Format == "" {
+		return YAMLDiscoveryFormat
+	}
+	return role.Discovery.Format
+}
+
+// GetDiscoveryMountPath returns the directory the discovery config of the role is mounted at.
+func (role *RoleSpec) GetDiscoveryMountPath() string {
+	if role.Discovery == nil || role.Discovery.MountPath == "" {
+		return "/etc/rbg"
+	}
+	return role.Discovery.MountPath
+}
+
+// GetDiscoveryFileName returns the file name of the discovery config of the role.
+func (role *RoleSpec) GetDiscoveryFileName() string {
+	if role.Discovery != nil && role.Discovery.FileName != "" {
+		return role.Discovery.FileName
+	}
+	switch role.GetDiscoveryFormat() {
+	case JSONDiscoveryFormat:
+		return "config.json"
+	case EnvDiscoveryFormat:
+		return "config.env"
+	case TemplateDiscoveryFormat:
+		return "config"
+	default:
+		return "config.yaml"
+	}
+}
+
+// MountSetDiscoveryConfig reports whether the aggregated discovery config of the RoleBasedGroupSet is mounted into the
+// pods of the role.
+func (role *RoleSpec) MountSetDiscoveryConfig() bool {
+	return role.Discovery != nil && role.Discovery.SetConfig != nil
+}
+
+// GetSetDiscoveryMountPath returns the directory the aggregated discovery config of the RoleBasedGroupSet is mounted at.
+func (role *RoleSpec) GetSetDiscoveryMountPath() string {
+	if !role.MountSetDiscoveryConfig() || role.Discovery.SetConfig.MountPath == "" {
+		return "/etc/rbg-set"
+	}
+	return role.Discovery.SetConfig.MountPath
+}
+
+// InjectConfigEnabled reports whether the discovery config is injected into the pods of the role.
+func (role *RoleSpec) InjectConfigEnabled() bool {
+	return role.Injection == nil || role.Injection.Config == nil || *role.Injection.Config
+}
+
+// InjectSidecarEnabled reports whether the engine runtime sidecars are injected into the pods of the role.
+func (role *RoleSpec) InjectSidecarEnabled() bool {
+	return role.Injection == nil || role.Injection.Sidecar == nil || *role.Injection.Sidecar
+}
+
+// InjectEnvEnabled reports whether the env vars are injected into the pods of the role.
+func (role *RoleSpec) InjectEnvEnabled() bool {
+	return role.Injection == nil || role.Injection.Env == nil || *role.Injection.Env
+}
+
+// OverrideInjectedEnv reports whether the injected env vars replace the env vars defined by the user.
+func (role *RoleSpec) OverrideInjectedEnv() bool {
+	return role.Injection != nil && role.Injection.EnvConflictPolicy == OverrideEnvConflictPolicy
+}
+
+// RenderTemplatesEnabled reports whether the Go templates in the env values and args of the role are rendered.
+func (role *RoleSpec) RenderTemplatesEnabled() bool {
+	return role.Injection != nil && role.Injection.RenderTemplates
+}
+
+// DependencyRoles returns the names of the roles role depends on, in dependencies and in dependsOn.
+func (role *RoleSpec) DependencyRoles() []string {
+	roles := append([]string(nil), role.Dependencies...)
+	for _, dep := range role.DependsOn {
+		if dep.Role != "" && !slices.Contains(roles, dep.Role) {
+			roles = append(roles, dep.Role)
+		}
+	}
+	return roles
+}
+
+// GetCondition returns the condition the dependency waits for.
+func (dep *Dependency) GetCondition() DependencyCondition {
+	if dep.Condition == "" {
+		return ReadyDependencyCondition
+	}
+	return dep.Condition
+}
+
+// RegistersBackends reports whether any role of rbg registers the instances of other roles.
+func (rbg *RoleBasedGroup) RegistersBackends() bool {
+	for _, role := range rbg.Spec.Roles {
+		if role.Registration != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (rbg *RoleBasedGroup) GetRole(roleName string) (*RoleSpec, error) {
 	if roleName == "" {
 		return nil, errors.New("roleName cannot be empty")
@@ -42,9 +42,27 @@ type RoleBasedGroupScalingAdapterStatus struct {
 	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
 }
 
+type AdapterScaleTargetKind string
+
+const (
+	// AdapterScaleTargetRBG scales a role of a RoleBasedGroup.
+	AdapterScaleTargetRBG AdapterScaleTargetKind = "RoleBasedGroup"
+
+	// AdapterScaleTargetRBGSet scales the number of RoleBasedGroups of a RoleBasedGroupSet.
+	AdapterScaleTargetRBGSet AdapterScaleTargetKind = "RoleBasedGroupSet"
+)
+
 type AdapterScaleTargetRef struct {
+	// Kind of the scale target, the role of a RoleBasedGroup is scaled by default.
+	// +kubebuilder:validation:Enum={RoleBasedGroup,RoleBasedGroupSet}
+	// +optional
+	Kind AdapterScaleTargetKind `json:"kind,omitempty"`
+
 	Name string `json:"name"`
-	Role string `json:"role"`
+
+	// Role is the name of the scaled role, it is required if the kind is RoleBasedGroup.
+	// +optional
+	Role string `json:"role,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -26,14 +26,118 @@ import (
 )
 
 // RoleBasedGroupSpec defines the desired state of RoleBasedGroup.
+// +kubebuilder:validation:XValidation:rule="!has(self.disruptionBudget) || self.roles.all(r, !has(r.disruptionBudget))",message="disruptionBudget of the group and of the roles are mutually exclusive"
 type RoleBasedGroupSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:Required
-	Roles []RoleSpec `json:"roles"`
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Roles []RoleSpec `json:"roles" patchStrategy:"merge" patchMergeKey:"name"`
 
 	// Configuration for the PodGroup to enable gang-scheduling via supported plugins.
 	PodGroupPolicy *PodGroupPolicy `json:"podGroupPolicy,omitempty"`
+
+	// DisruptionBudget limits the voluntary disruptions of all pods of the group, e.g. by node drains.
+	// A PodDisruptionBudget selecting the pods of all roles is created if it is set.
+	// It is mutually exclusive with the disruption budgets of the roles, since a pod selected by two
+	// PodDisruptionBudgets cannot be evicted.
+	// +optional
+	DisruptionBudget *DisruptionBudget `json:"disruptionBudget,omitempty"`
+
+	// Expose publishes a role of the group, e.g. the router, through Gateway API once the group is ready.
+	// +optional
+	Expose *ExposeSpec `json:"expose,omitempty"`
+
+	// Discovery configures the discovery config injected into the pods of the group.
+	// +optional
+	Discovery *DiscoveryPolicy `json:"discovery,omitempty"`
+}
+
+// DiscoveryPolicy defines how the instances of the roles are listed in the discovery config.
+type DiscoveryPolicy struct {
+	// Source of the instances. Static lists the synthesized DNS names of all desired replicas. Pods lists the
+	// observed pods of the roles, and the discovery config is updated as the pods come and go.
+	// +kubebuilder:validation:Enum={Static,Pods}
+	// +kubebuilder:default=Static
+	// +optional
+	Source DiscoverySource `json:"source,omitempty"`
+
+	// AddressType of the instances observed from pods. IP uses the pod IP. DNS uses the stable DNS name of the pods
+	// of StatefulSet and LeaderWorkerSet roles, and the pod IP for the other pods.
+	// +kubebuilder:validation:Enum={IP,DNS}
+	// +kubebuilder:default=IP
+	// +optional
+	AddressType DiscoveryAddressType `json:"addressType,omitempty"`
+
+	// IncludeNotReady lists the observed pods which are not ready yet, with their readiness.
+	// +optional
+	IncludeNotReady bool `json:"includeNotReady,omitempty"`
+}
+
+// ExposeSpec defines the Gateway API objects publishing a role of the group. They are named after the group.
+type ExposeSpec struct {
+	// Role is the name of the exposed role.
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+
+	// Port of the role the traffic is forwarded to. Default to the first servicePort of the role.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// HTTPRoute creates a Gateway API HTTPRoute forwarding to the Service of the role, or to the InferencePool
+	// if it is enabled.
+	// +optional
+	HTTPRoute *ExposeHTTPRoute `json:"httpRoute,omitempty"`
+
+	// InferencePool creates a Gateway API Inference Extension InferencePool selecting the pods of the role.
+	// +optional
+	InferencePool *ExposeInferencePool `json:"inferencePool,omitempty"`
+}
+
+// ExposeHTTPRoute defines the HTTPRoute of an exposed role.
+type ExposeHTTPRoute struct {
+	// ParentRefs are the Gateways the route is attached to.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []ExposeParentReference `json:"parentRefs"`
+
+	// Hostnames matched by the route. If not set, all the hostnames of the Gateway are matched.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// PathPrefix matched by the route. Default to "/".
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+}
+
+// ExposeParentReference refers to a Gateway.
+type ExposeParentReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Default to the namespace of the group.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// ExposeInferencePool defines the InferencePool of an exposed role.
+type ExposeInferencePool struct {
+	// EndpointPicker is the name of the Service of the endpoint picker extension which picks the pod for
+	// each request.
+	// +kubebuilder:validation:MinLength=1
+	EndpointPicker string `json:"endpointPicker"`
+
+	// EndpointPickerPort is the port of the endpoint picker Service. Default to 9002.
+	// +optional
+	EndpointPickerPort *int32 `json:"endpointPickerPort,omitempty"`
+
+	// FailOpen forwards the requests to any pod of the pool if the endpoint picker is unavailable.
+	// +optional
+	FailOpen bool `json:"failOpen,omitempty"`
 }
 
 // PodGroupPolicy represents a PodGroup configuration for gang-scheduling.
@@ -127,14 +231,48 @@ type RoleSpec struct {
 
 	// RestartPolicy defines the restart policy when pod failures happen.
 	// The default value is RecreateRoleInstanceOnPodRestart for LWS and None for STS & Deploy. Therefore, no default value is set.
-	// +kubebuilder:validation:Enum={None,RecreateRBGOnPodRestart,RecreateRoleInstanceOnPodRestart}
+	// +kubebuilder:validation:Enum={None,RecreateRBGOnPodRestart,RecreateRoleInstanceOnPodRestart,RecreateDependentRolesOnPodRestart,RecreateDirectDependentRolesOnPodRestart}
 	// +optional
 	RestartPolicy RestartPolicyType `json:"restartPolicy,omitempty"`
 
+	// RestartBackoff limits how often the controller recreates workloads when the restart policy is triggered.
+	// If not set, restarts happen immediately and are unlimited.
+	// +optional
+	RestartBackoff *RestartBackoffPolicy `json:"restartBackoff,omitempty"`
+
+	// InstanceSize is the number of consecutive pods forming one instance of a StatefulSet role, e.g. the
+	// tensor-parallel shards of a model. Pods [i*instanceSize, (i+1)*instanceSize) belong to instance i.
+	// It is used by RecreateRoleInstanceOnPodRestart. Default to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InstanceSize *int32 `json:"instanceSize,omitempty"`
+
+	// FailureDetection configures the pod failures, other than pod deletion, eviction and container restart,
+	// which trigger the restart policy of the role.
+	// +optional
+	FailureDetection *FailureDetectionPolicy `json:"failureDetection,omitempty"`
+
+	// DisruptionBudget limits the voluntary disruptions of the pods of the role, e.g. by node drains.
+	// A PodDisruptionBudget selecting the pods of the role is created if it is set and the group has no
+	// disruption budget.
+	// +optional
+	DisruptionBudget *DisruptionBudget `json:"disruptionBudget,omitempty"`
+
 	// Dependencies of the role
 	// +optional
 	Dependencies []string `json:"dependencies,omitempty"`
 
+	// DependsOn are dependencies of the role with the condition to wait for, on other roles of the group or on
+	// objects in its namespace. The role waits for both dependencies and dependsOn.
+	// +optional
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// StartupBarrier injects an init container into the pods of the role, which blocks the containers of the role
+	// until its dependencies are ready. Unlike dependencies, which only order the creation of the workloads, it also
+	// holds the pods created afterwards, e.g. when they are recreated while a dependency restarts.
+	// +optional
+	StartupBarrier *StartupBarrier `json:"startupBarrier,omitempty"`
+
 	// Workload type specification
 	// +kubebuilder:default={apiVersion:"apps/v1", kind:"StatefulSet"}
 	// +optional
@@ -151,6 +289,26 @@ type RoleSpec struct {
 	// +optional
 	ServicePorts []corev1.ServicePort `json:"servicePorts,omitempty"`
 
+	// Service defines the Service of the role, whose ports are the servicePorts of the role.
+	// If not set, only StatefulSet roles get a headless Service.
+	// +optional
+	Service *RoleService `json:"service,omitempty"`
+
+	// Discovery defines the format and the location of the discovery config mounted into the pods of the role.
+	// If not set, the config is mounted at /etc/rbg/config.yaml in YAML.
+	// +optional
+	Discovery *RoleDiscoveryConfig `json:"discovery,omitempty"`
+
+	// Injection controls what the controller injects into the pods of the role.
+	// If not set, the discovery config, the engine runtime sidecars and the env vars are injected into all containers.
+	// +optional
+	Injection *InjectionPolicy `json:"injection,omitempty"`
+
+	// Registration registers the ready instances of other roles, e.g. the prefill and decode workers, into the HTTP
+	// API of the ready instances of this role, e.g. a router.
+	// +optional
+	Registration *RoleRegistration `json:"registration,omitempty"`
+
 	// +optional
 	EngineRuntimes []EngineRuntime `json:"engineRuntimes,omitempty"`
 
@@ -158,6 +316,297 @@ type RoleSpec struct {
 	ScalingAdapter *ScalingAdapter `json:"scalingAdapter,omitempty"`
 }
 
+// RoleDiscoveryConfig defines how the discovery config of the group is rendered and mounted for a role.
+// +kubebuilder:validation:XValidation:rule="!has(self.format) || self.format != 'Template' || has(self.template)",message="template is required when format is Template"
+type RoleDiscoveryConfig struct {
+	// Format of the config. YAML and JSON marshal the config, Env writes it as KEY=value lines and Template
+	// renders it with the Go text/template in Template.
+	// +kubebuilder:validation:Enum={YAML,JSON,Env,Template}
+	// +kubebuilder:default=YAML
+	// +optional
+	Format DiscoveryFormat `json:"format,omitempty"`
+
+	// Template renders the config when the format is Template.
+	// +optional
+	Template *DiscoveryTemplate `json:"template,omitempty"`
+
+	// MountPath is the directory the config is mounted at. Default to /etc/rbg.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// FileName is the name of the config file. Default to config.yaml, config.json, config.env or config
+	// according to the format.
+	// +kubebuilder:validation:Pattern=`^[^/]+$`
+	// +optional
+	FileName string `json:"fileName,omitempty"`
+
+	// SetConfig mounts the aggregated discovery config of the RoleBasedGroupSet of the group, listing the roles and
+	// instances of all its RoleBasedGroups, e.g. for a router shared by the set. It is ignored if the group does not
+	// belong to a RoleBasedGroupSet.
+	// +optional
+	SetConfig *SetDiscoveryConfig `json:"setConfig,omitempty"`
+}
+
+// SetDiscoveryConfig defines where the aggregated discovery config of a RoleBasedGroupSet is mounted.
+type SetDiscoveryConfig struct {
+	// MountPath is the directory the config is mounted at, it is different from the directory of the discovery
+	// config of the group. Default to /etc/rbg-set.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// DiscoveryTemplate references a Go text/template stored in a ConfigMap in the namespace of the group.
+// The template is executed with the discovery config, e.g. {{ .Group.Name }} and {{ range .Roles.prefill.Instances }}.
+type DiscoveryTemplate struct {
+	// ConfigMapName is the name of the ConfigMap holding the template.
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+
+	// Key of the template in the ConfigMap. Default to "template".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// InjectionPolicy defines what the controller injects into the pods of a role, and into which containers.
+type InjectionPolicy struct {
+	// Config mounts the discovery config of the group. Default to true.
+	// +optional
+	Config *bool `json:"config,omitempty"`
+
+	// Sidecar injects the containers of the engine runtimes of the role. Default to true.
+	// +optional
+	Sidecar *bool `json:"sidecar,omitempty"`
+
+	// Env injects the env vars GROUP_NAME, ROLE_NAME and ROLE_INDEX. Default to true.
+	// +optional
+	Env *bool `json:"env,omitempty"`
+
+	// Containers limits the injection of the config and the env vars to the named containers and init containers.
+	// If empty, they are injected into all containers but not into the init containers.
+	// +optional
+	Containers []string `json:"containers,omitempty"`
+
+	// EnvConflictPolicy decides which value is used when an injected env var is also defined in a container.
+	// Preserve keeps the value defined by the user, Override replaces it with the injected value.
+	// +kubebuilder:validation:Enum={Preserve,Override}
+	// +kubebuilder:default=Preserve
+	// +optional
+	EnvConflictPolicy EnvConflictPolicy `json:"envConflictPolicy,omitempty"`
+
+	// RenderTemplates renders the Go templates in the env values and the args of the containers, e.g.
+	// {{ .Group.Name }} or {{ join (addresses .Roles.prefill.Instances) "," }}, with the discovery config of the
+	// group. The templates are rendered from the spec of the group rather than the observed pods, so that the pods
+	// are only rolled out when the rendered values change.
+	// +optional
+	RenderTemplates bool `json:"renderTemplates,omitempty"`
+}
+
+// Dependency is a role of the group or an object the role waits for.
+// +kubebuilder:validation:XValidation:rule="has(self.role) != has(self.object)",message="exactly one of role and object is required"
+// +kubebuilder:validation:XValidation:rule="!has(self.condition) || self.condition != 'Completed' || (has(self.object) && self.object.kind == 'Job')",message="condition Completed is only supported for Job"
+type Dependency struct {
+	// Role is the name of a role of the group.
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// Object is an object in the namespace of the group.
+	// +optional
+	Object *DependencyObject `json:"object,omitempty"`
+
+	// Condition is what the dependency waits for:
+	// Created waits until the workload of the role or the object exists.
+	// Ready waits until the threshold of the replicas of the role are ready, the Secret or ConfigMap exists, the
+	// PersistentVolumeClaim is Bound, the threshold of the pods of the Job are ready, or the RoleBasedGroup is Ready.
+	// Completed waits until the Job is Complete.
+	// +kubebuilder:validation:Enum={Created,Ready,Completed}
+	// +kubebuilder:default=Ready
+	// +optional
+	Condition DependencyCondition `json:"condition,omitempty"`
+
+	// Threshold is the number or the percentage of the replicas of the role, or of the parallelism of the Job, which
+	// must be ready for the condition Ready, capped at the replicas. Default to 100%.
+	// +optional
+	Threshold *intstr.IntOrString `json:"threshold,omitempty"`
+}
+
+// DependencyCondition is the condition of a dependency.
+type DependencyCondition string
+
+const (
+	CreatedDependencyCondition   DependencyCondition = "Created"
+	ReadyDependencyCondition     DependencyCondition = "Ready"
+	CompletedDependencyCondition DependencyCondition = "Completed"
+)
+
+// DependencyObject refers to an object in the namespace of the group.
+type DependencyObject struct {
+	// Kind of the object.
+	// +kubebuilder:validation:Enum={Secret,ConfigMap,PersistentVolumeClaim,Job,RoleBasedGroup}
+	Kind DependencyObjectKind `json:"kind"`
+
+	// Name of the object.
+	Name string `json:"name"`
+}
+
+// DependencyObjectKind is the kind of an object a role depends on.
+type DependencyObjectKind string
+
+const (
+	SecretDependencyObject                DependencyObjectKind = "Secret"
+	ConfigMapDependencyObject             DependencyObjectKind = "ConfigMap"
+	PersistentVolumeClaimDependencyObject DependencyObjectKind = "PersistentVolumeClaim"
+	JobDependencyObject                   DependencyObjectKind = "Job"
+	RoleBasedGroupDependencyObject        DependencyObjectKind = "RoleBasedGroup"
+)
+
+// StartupBarrier defines the init container blocking the pods of a role until the dependencies of the role are ready.
+type StartupBarrier struct {
+	// Image of the init container, which runs /rbg-agent, e.g. the image of the controller.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// TimeoutSeconds fails the init container if the dependencies are not ready in time, so that the pod is
+	// restarted according to its restart policy. If not set, the init container waits forever.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// RoleRegistration defines how the instances of the backend roles are registered into the instances of a role.
+type RoleRegistration struct {
+	// Backends are the roles whose ready instances are registered.
+	// +kubebuilder:validation:MinItems=1
+	Backends []RegistrationBackend `json:"backends"`
+
+	// Port is the port of the registration API on the pods of the role.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Register is the request registering a backend.
+	Register RegistrationRequest `json:"register"`
+
+	// Deregister is the request deregistering a backend. If not set, the backends are only forgotten when they are
+	// not ready anymore, e.g. for a router which drops the failing backends by itself.
+	// +optional
+	Deregister *RegistrationRequest `json:"deregister,omitempty"`
+}
+
+// RegistrationBackend is a role whose instances are registered.
+type RegistrationBackend struct {
+	// Role is the name of the backend role.
+	Role string `json:"role"`
+
+	// Port is the port of the backend registered. Default to the first service port of the role.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// RegistrationRequest is a HTTP request sent to the registration API. The path and the body are Go templates
+// rendered with the backend, e.g. /add_worker?url={{ urlquery .URL }}, whose fields are Role, Name, Address, Port
+// and URL.
+type RegistrationRequest struct {
+	// Method of the request.
+	// +kubebuilder:validation:Enum={GET,POST,PUT,DELETE}
+	// +kubebuilder:default=POST
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Path of the request, including the query.
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// Body of the request, sent as JSON.
+	// +optional
+	Body string `json:"body,omitempty"`
+}
+
+// RestartBackoffPolicy defines the exponential backoff and restart budget applied to restarts
+// performed by the rbg controller.
+type RestartBackoffPolicy struct {
+	// InitialDelaySeconds is the delay before the second restart within a budget window. The first
+	// restart always happens immediately. The delay doubles for every subsequent restart.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// MaxDelaySeconds caps the exponential backoff delay.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300
+	// +optional
+	MaxDelaySeconds *int32 `json:"maxDelaySeconds,omitempty"`
+
+	// MaxRestarts is the maximum number of restarts allowed within WindowSeconds.
+	// Once the budget is used up, the rbg is marked as Failed and no more restarts are performed.
+	// 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+
+	// WindowSeconds is the time window of the restart budget. The restart counter of a role is reset
+	// when no restart has happened for WindowSeconds.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3600
+	// +optional
+	WindowSeconds *int32 `json:"windowSeconds,omitempty"`
+}
+
+// RoleService defines the Service of a role. A headless Service is named after the workload of the role, the
+// Services of the other types are named "<workload>-svc" so that they don't conflict with the headless Service
+// of StatefulSet and LeaderWorkerSet roles.
+type RoleService struct {
+	// Type of the Service. The headless Service of LeaderWorkerSet roles is managed by LeaderWorkerSet.
+	// +kubebuilder:validation:Enum={Headless,ClusterIP,LoadBalancer,NodePort}
+	// +kubebuilder:default=Headless
+	// +optional
+	Type RoleServiceType `json:"type,omitempty"`
+
+	// Annotations added to the Service, e.g. the load balancer configuration of the cloud provider.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// SessionAffinity of the Service.
+	// +kubebuilder:validation:Enum={ClientIP,None}
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityConfig contains the configurations of the ClientIP session affinity.
+	// +optional
+	SessionAffinityConfig *corev1.SessionAffinityConfig `json:"sessionAffinityConfig,omitempty"`
+}
+
+// DisruptionBudget defines the PodDisruptionBudget of a role or of a group.
+// Only one of MinAvailable and MaxUnavailable may be set. If neither is set, MaxUnavailable defaults to 1.
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type DisruptionBudget struct {
+	// MinAvailable is the number or percentage of the selected pods that must stay available after an eviction.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of the selected pods that can be unavailable after an eviction.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// FailureDetectionPolicy defines when a pod that is neither deleted nor restarted is treated as failed.
+type FailureDetectionPolicy struct {
+	// NodeNotReadyGracePeriodSeconds is how long the node of a pod may stay NotReady before the pod is
+	// treated as failed. Pods on a deleted node are treated as failed immediately.
+	// If not set, pods on NotReady nodes are not treated as failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NodeNotReadyGracePeriodSeconds *int32 `json:"nodeNotReadyGracePeriodSeconds,omitempty"`
+
+	// PendingGracePeriodSeconds is how long a pod may stay Pending before it is treated as failed.
+	// If not set, pending pods are not treated as failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	PendingGracePeriodSeconds *int32 `json:"pendingGracePeriodSeconds,omitempty"`
+}
+
 type WorkloadSpec struct {
 	// +optional
 	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/v[0-9]+((alpha|beta)[0-9]+)?$`
@@ -227,6 +676,58 @@ type RoleBasedGroupStatus struct {
 
 	// Status of individual roles
 	RoleStatuses []RoleStatus `json:"roleStatuses"`
+
+	// Registrations are the backends registered into the ready instances of the roles with registration.
+	// +optional
+	Registrations []RegistrationStatus `json:"registrations,omitempty"`
+}
+
+// RegistrationStatus shows the backends registered into an instance of a role.
+type RegistrationStatus struct {
+	// Role of the instance
+	Role string `json:"role"`
+
+	// Instance is the name of the pod the backends are registered into
+	Instance string `json:"instance"`
+
+	// ReadyTime is the time the instance became ready. The backends are registered again when it changes, e.g. when
+	// the instance restarts and loses its registrations.
+	// +optional
+	ReadyTime *metav1.Time `json:"readyTime,omitempty"`
+
+	// Backends registered into the instance
+	// +optional
+	Backends []RegisteredBackend `json:"backends,omitempty"`
+
+	// Pending are the backends whose register or deregister request is about to be sent. They are recorded before
+	// the requests, so that a backend is neither registered twice nor left registered if the result of a request is
+	// lost. A pending backend is deregistered before it is registered again.
+	// +optional
+	Pending []RegisteredBackend `json:"pending,omitempty"`
+
+	// Port of the registration API of the instance, with which the backends are deregistered once the registration
+	// of the role is removed.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Deregister is the request deregistering the backends once the registration of the role is removed.
+	// +optional
+	Deregister *RegistrationRequest `json:"deregister,omitempty"`
+}
+
+// RegisteredBackend is a backend registered into an instance.
+type RegisteredBackend struct {
+	// Role of the backend
+	Role string `json:"role"`
+
+	// Name of the pod of the backend, the leader pod for a LeaderWorkerSet role
+	Name string `json:"name"`
+
+	// Address of the backend, as in the discovery config
+	Address string `json:"address"`
+
+	// Port of the backend
+	Port int32 `json:"port"`
 }
 
 // RoleStatus shows the current state of a specific role
@@ -239,6 +740,23 @@ type RoleStatus struct {
 
 	// Total number of desired replicas
 	Replicas int32 `json:"replicas"`
+
+	// Number of replicas which run the latest revision of the role
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Number of restarts triggered by this role, within the current restart budget window if the role
+	// has a restart backoff policy
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// Time of the last restart triggered by this role
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
+	// Reason of the last restart triggered by this role
+	// +optional
+	LastRestartReason string `json:"lastRestartReason,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -279,6 +797,14 @@ const (
 	// RoleBasedGroupRestartInProgress means rbg is restarting. RestartInProgress
 	// is true when the rbg is in restart process after the pod is deleted or the container is restarted.
 	RoleBasedGroupRestartInProgress RoleBasedGroupConditionType = "RestartInProgress"
+
+	// RoleBasedGroupFailed means the rbg has used up the restart budget of a role. The controller stops
+	// restarting the rbg until the spec of the rbg is updated.
+	RoleBasedGroupFailed RoleBasedGroupConditionType = "Failed"
+
+	// RoleBasedGroupExposed means the Gateway API objects of spec.expose are created and the HTTPRoute is
+	// accepted by all its Gateways.
+	RoleBasedGroupExposed RoleBasedGroupConditionType = "Exposed"
 )
 
 // +kubebuilder:object:root=true
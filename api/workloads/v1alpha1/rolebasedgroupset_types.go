@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // RoleBasedGroupSetSpec defines the desired state of RoleBasedGroupSet.
@@ -28,12 +30,193 @@ type RoleBasedGroupSetSpec struct {
 
 	// Template describes the RoleBasedGroup that will be created.
 	Template RoleBasedGroupSpec `json:"template"`
+
+	// Overrides customize the template for the RoleBasedGroups of some indices, e.g. to place them
+	// in different zones or on different GPU flavors. The overrides are applied in order, a later
+	// override wins over an earlier one.
+	// +optional
+	Overrides []RoleBasedGroupSetOverride `json:"overrides,omitempty"`
+
+	// TopologySpread spreads the RoleBasedGroups across topology domains such as zones or racks.
+	// +optional
+	TopologySpread *RoleBasedGroupSetTopologySpread `json:"topologySpread,omitempty"`
+
+	// RolloutStrategy defines the strategy that will be applied to update the existing
+	// RoleBasedGroups when the template is changed.
+	// +optional
+	RolloutStrategy *RoleBasedGroupSetRolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// ScaleStrategy defines which RoleBasedGroups are removed when the set is scaled down
+	// and how they are drained before deletion.
+	// +optional
+	ScaleStrategy *RoleBasedGroupSetScaleStrategy `json:"scaleStrategy,omitempty"`
+
+	// PodGroupPolicy gang-schedules the pods of all RoleBasedGroups of the set with one PodGroup named after
+	// the set, whose min members are the total group size of the RoleBasedGroups. The RoleBasedGroups do not
+	// create their own PodGroups then.
+	// +optional
+	PodGroupPolicy *PodGroupPolicy `json:"podGroupPolicy,omitempty"`
+
+	// ScalingAdapter binds a RoleBasedGroupScalingAdapter named <rbgset>.scaling-adapter to the replicas of the
+	// set, so that autoscalers like HPA or KEDA can scale the set through the adapter.
+	// +optional
+	ScalingAdapter *ScalingAdapter `json:"scalingAdapter,omitempty"`
+}
+
+// RoleBasedGroupSetOverride patches the template for the RoleBasedGroups of the selected indices.
+// An override applies to an index if the index is listed in Indices or falls in IndexRange.
+type RoleBasedGroupSetOverride struct {
+	// Indices lists the indices of the RoleBasedGroups the override applies to.
+	// +optional
+	Indices []int32 `json:"indices,omitempty"`
+
+	// IndexRange selects the RoleBasedGroups with an index in the range.
+	// +optional
+	IndexRange *IndexRange `json:"indexRange,omitempty"`
+
+	// Patch is a strategic merge patch applied on the template, roles are merged by name.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Patch runtime.RawExtension `json:"patch,omitempty"`
+}
+
+// IndexRange selects the indices in [Start, End].
+type IndexRange struct {
+	// Start is the first index of the range.
+	// +kubebuilder:validation:Minimum=0
+	Start int32 `json:"start"`
+
+	// End is the last index of the range, the range is unbounded if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	End *int32 `json:"end,omitempty"`
+}
+
+// RoleBasedGroupSetTopologySpread pins the RoleBasedGroup of index i to the topology domain Values[i % len(Values)].
+// The domain is injected into the pod templates of all roles as a required node affinity, before the overrides
+// are applied.
+type RoleBasedGroupSetTopologySpread struct {
+	// TopologyKey is the key of the node label which identifies the domains, e.g. topology.kubernetes.io/zone.
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+
+	// Values are the domains the RoleBasedGroups are spread across in rotation.
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+type ScaleDownPolicyType string
+
+const (
+	// HighestIndexFirst removes the RoleBasedGroups with the highest index first.
+	HighestIndexFirst ScaleDownPolicyType = "HighestIndexFirst"
+
+	// NotReadyFirst removes the RoleBasedGroups which are not ready first, and then the ones
+	// with the highest index.
+	NotReadyFirst ScaleDownPolicyType = "NotReadyFirst"
+)
+
+// RoleBasedGroupSetScaleStrategy defines the strategy that the rbgset controller will use to
+// scale down the RoleBasedGroups of the set.
+// The RoleBasedGroups listed in IndicesToDelete are always removed first. Among the others, the
+// ScaleDownPolicy decides first, then the RoleBasedGroups with a lower deletion cost annotation
+// (rolebasedgroupset.workloads.x-k8s.io/deletion-cost) are removed first.
+type RoleBasedGroupSetScaleStrategy struct {
+	// ScaleDownPolicy defines which RoleBasedGroups are preferred to be removed.
+	// +kubebuilder:validation:Enum={HighestIndexFirst,NotReadyFirst}
+	// +kubebuilder:default=HighestIndexFirst
+	// +optional
+	ScaleDownPolicy ScaleDownPolicyType `json:"scaleDownPolicy,omitempty"`
+
+	// IndicesToDelete lists the indices of the RoleBasedGroups to remove first when the set
+	// is scaled down.
+	// +optional
+	IndicesToDelete []int32 `json:"indicesToDelete,omitempty"`
+
+	// Drain defines how a RoleBasedGroup is drained before it is deleted. The RoleBasedGroups
+	// are deleted immediately if not set.
+	// +optional
+	Drain *RoleBasedGroupSetDrainPolicy `json:"drain,omitempty"`
+}
+
+// RoleBasedGroupSetDrainPolicy defines the drain phase of a RoleBasedGroup removed by scale-down.
+// A draining RoleBasedGroup is marked with the rolebasedgroupset.workloads.x-k8s.io/draining-since
+// annotation, is no longer counted in the replicas of the set, and is deleted after the grace period.
+type RoleBasedGroupSetDrainPolicy struct {
+	// GracePeriodSeconds is the time a RoleBasedGroup is kept draining before deletion, so that
+	// the in-flight requests can finish.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=30
+	GracePeriodSeconds *int32 `json:"gracePeriodSeconds,omitempty"`
+
+	// RoutingLabels are removed from the pods of a draining RoleBasedGroup, so that the pods are
+	// removed from the Services or routers which select them by these labels.
+	// The labels must not be used by the selectors of the workloads.
+	// +optional
+	RoutingLabels []string `json:"routingLabels,omitempty"`
+}
+
+// RoleBasedGroupSetRolloutStrategy defines the strategy that the rbgset controller
+// will use to update the RoleBasedGroups of the set.
+type RoleBasedGroupSetRolloutStrategy struct {
+	// Type defines the rollout strategy, it can only be “RollingUpdate” for now.
+	//
+	// +kubebuilder:validation:Enum={RollingUpdate}
+	// +kubebuilder:default=RollingUpdate
+	Type RolloutStrategyType `json:"type"`
+
+	// RollingUpdate defines the parameters to be used when type is RollingUpdateStrategyType.
+	// +optional
+	RollingUpdate *RoleBasedGroupSetRollingUpdate `json:"rollingUpdate,omitempty"`
+}
+
+// RoleBasedGroupSetRollingUpdate defines the parameters to be used for RollingUpdateStrategyType.
+// The RoleBasedGroups are updated in the order of their index, and a RoleBasedGroup counts as
+// available when its Ready condition is true for its latest generation.
+type RoleBasedGroupSetRollingUpdate struct {
+	// The maximum number of RoleBasedGroups that can be unavailable during the update.
+	// Value can be an absolute number (ex: 5) or a percentage of the desired replicas (ex: 10%).
+	// Absolute number is calculated from percentage by rounding down.
+	// When both MaxUnavailable and MaxSurge are 0, a value of 1 is used.
+	// By default, a fixed value of 1 is used.
+	//
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:default=1
+	MaxUnavailable intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// The maximum number of RoleBasedGroups that can be created above the desired replicas
+	// during the update. The surge RoleBasedGroups are created with the latest template and
+	// removed once the update completes.
+	// Value can be an absolute number (ex: 5) or a percentage of the desired replicas (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	// By default, a value of 0 is used.
+	//
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:default=0
+	MaxSurge intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// Partition indicates the ordinal at which the RoleBasedGroupSet should be partitioned
+	// for updates. Only the RoleBasedGroups with an index greater than or equal to the
+	// partition are updated, the others keep their current spec.
+	// By default, a value of 0 is used.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
 }
 
 type RoleBasedGroupSetConditionType string
 
 const (
 	RoleBasedGroupSetReady RoleBasedGroupSetConditionType = "Ready"
+
+	// RoleBasedGroupSetProgressing means the set is scaling, updating, or waiting for its RoleBasedGroups
+	// to become ready.
+	RoleBasedGroupSetProgressing RoleBasedGroupSetConditionType = "Progressing"
+
+	// RoleBasedGroupSetRollingUpdateInProgress means some RoleBasedGroups are not updated to the latest revision.
+	RoleBasedGroupSetRollingUpdateInProgress RoleBasedGroupSetConditionType = "RollingUpdateInProgress"
 )
 
 // RoleBasedGroupSetStatus defines the observed state of RoleBasedGroupSet.
@@ -48,15 +231,107 @@ type RoleBasedGroupSetStatus struct {
 	// +optional
 	ReadyReplicas int32 `json:"readyReplicas" protobuf:"varint,3,opt,name=readyReplicas"`
 
+	// The number of RoleBasedGroups whose spec matches the current template.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// UpdateRevision is the revision of the latest template, overrides, topology spread and pod group policy
+	// of the set.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// Revisions reports the number of RoleBasedGroups on each revision.
+	// +optional
+	Revisions []RoleBasedGroupSetRevisionStatus `json:"revisions,omitempty"`
+
+	// RoleStatuses aggregates the status of each role across the RoleBasedGroups.
+	// +optional
+	RoleStatuses []RoleBasedGroupSetRoleStatus `json:"roleStatuses,omitempty"`
+
+	// Selector is the label selector of the pods of all RoleBasedGroups of the set, it is
+	// used by the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// IndexStatuses reports the effective spec of the RoleBasedGroup of each index.
+	// +optional
+	IndexStatuses []RoleBasedGroupSetIndexStatus `json:"indexStatuses,omitempty"`
+
+	// TopologyDistribution reports how the RoleBasedGroups are distributed across the topology domains.
+	// +optional
+	TopologyDistribution []TopologyDomainStatus `json:"topologyDistribution,omitempty"`
+
 	// Conditions track the condition of the rbgs
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// RoleBasedGroupSetIndexStatus reports the RoleBasedGroup of an index of the set.
+type RoleBasedGroupSetIndexStatus struct {
+	// Index of the RoleBasedGroup
+	Index int32 `json:"index"`
+
+	// Name of the RoleBasedGroup
+	Name string `json:"name"`
+
+	// SpecHash is the hash of the effective spec of the index, i.e. the template with the overrides
+	// of the index applied.
+	SpecHash string `json:"specHash"`
+
+	// Updated is true if the spec of the RoleBasedGroup matches the effective spec of the index.
+	// +optional
+	Updated bool `json:"updated,omitempty"`
+
+	// Ready is true if the Ready condition of the RoleBasedGroup is true.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Revision of the set the RoleBasedGroup was last updated to.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+}
+
+// RoleBasedGroupSetRevisionStatus reports the RoleBasedGroups of a revision.
+type RoleBasedGroupSetRevisionStatus struct {
+	// Revision of the set
+	Revision string `json:"revision"`
+
+	// Number of RoleBasedGroups on the revision
+	Replicas int32 `json:"replicas"`
+}
+
+// RoleBasedGroupSetRoleStatus aggregates the status of a role across the RoleBasedGroups of the set.
+type RoleBasedGroupSetRoleStatus struct {
+	// Name of the role
+	Name string `json:"name"`
+
+	// Total replicas of the role
+	Replicas int32 `json:"replicas"`
+
+	// Total ready replicas of the role
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Total replicas of the role running the latest revision of their RoleBasedGroup
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+}
+
+// TopologyDomainStatus reports the RoleBasedGroups of a topology domain.
+type TopologyDomainStatus struct {
+	// Value of the topology domain
+	Value string `json:"value"`
+
+	// Number of RoleBasedGroups pinned to the domain
+	Replicas int32 `json:"replicas"`
+
+	// Number of ready RoleBasedGroups pinned to the domain
+	ReadyReplicas int32 `json:"readyReplicas"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:printcolumn:name="DESIRED",type="string",JSONPath=".status.replicas",description="desired replicas"
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.readyReplicas",description="ready replicas"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -151,6 +152,101 @@ func (in *ClusterEngineRuntimeProfileStatus) DeepCopy() *ClusterEngineRuntimePro
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dependency) DeepCopyInto(out *Dependency) {
+	*out = *in
+	if in.Object != nil {
+		in, out := &in.Object, &out.Object
+		*out = new(DependencyObject)
+		**out = **in
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependency.
+func (in *Dependency) DeepCopy() *Dependency {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyObject) DeepCopyInto(out *DependencyObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyObject.
+func (in *DependencyObject) DeepCopy() *DependencyObject {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryPolicy) DeepCopyInto(out *DiscoveryPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryPolicy.
+func (in *DiscoveryPolicy) DeepCopy() *DiscoveryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveryTemplate) DeepCopyInto(out *DiscoveryTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveryTemplate.
+func (in *DiscoveryTemplate) DeepCopy() *DiscoveryTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveryTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
+func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EngineRuntime) DeepCopyInto(out *EngineRuntime) {
 	*out = *in
@@ -178,6 +274,176 @@ func (in *EngineRuntime) DeepCopy() *EngineRuntime {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposeHTTPRoute) DeepCopyInto(out *ExposeHTTPRoute) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]ExposeParentReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeHTTPRoute.
+func (in *ExposeHTTPRoute) DeepCopy() *ExposeHTTPRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposeHTTPRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposeInferencePool) DeepCopyInto(out *ExposeInferencePool) {
+	*out = *in
+	if in.EndpointPickerPort != nil {
+		in, out := &in.EndpointPickerPort, &out.EndpointPickerPort
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeInferencePool.
+func (in *ExposeInferencePool) DeepCopy() *ExposeInferencePool {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposeInferencePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposeParentReference) DeepCopyInto(out *ExposeParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeParentReference.
+func (in *ExposeParentReference) DeepCopy() *ExposeParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposeParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposeSpec) DeepCopyInto(out *ExposeSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HTTPRoute != nil {
+		in, out := &in.HTTPRoute, &out.HTTPRoute
+		*out = new(ExposeHTTPRoute)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InferencePool != nil {
+		in, out := &in.InferencePool, &out.InferencePool
+		*out = new(ExposeInferencePool)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeSpec.
+func (in *ExposeSpec) DeepCopy() *ExposeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureDetectionPolicy) DeepCopyInto(out *FailureDetectionPolicy) {
+	*out = *in
+	if in.NodeNotReadyGracePeriodSeconds != nil {
+		in, out := &in.NodeNotReadyGracePeriodSeconds, &out.NodeNotReadyGracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PendingGracePeriodSeconds != nil {
+		in, out := &in.PendingGracePeriodSeconds, &out.PendingGracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureDetectionPolicy.
+func (in *FailureDetectionPolicy) DeepCopy() *FailureDetectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureDetectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexRange) DeepCopyInto(out *IndexRange) {
+	*out = *in
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexRange.
+func (in *IndexRange) DeepCopy() *IndexRange {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InjectionPolicy) DeepCopyInto(out *InjectionPolicy) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InjectionPolicy.
+func (in *InjectionPolicy) DeepCopy() *InjectionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(InjectionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeSchedulingPodGroupPolicySource) DeepCopyInto(out *KubeSchedulingPodGroupPolicySource) {
 	*out = *in
@@ -256,6 +522,115 @@ func (in *PodGroupPolicySource) DeepCopy() *PodGroupPolicySource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegisteredBackend) DeepCopyInto(out *RegisteredBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegisteredBackend.
+func (in *RegisteredBackend) DeepCopy() *RegisteredBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(RegisteredBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrationBackend) DeepCopyInto(out *RegistrationBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrationBackend.
+func (in *RegistrationBackend) DeepCopy() *RegistrationBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrationBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrationRequest) DeepCopyInto(out *RegistrationRequest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrationRequest.
+func (in *RegistrationRequest) DeepCopy() *RegistrationRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrationRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrationStatus) DeepCopyInto(out *RegistrationStatus) {
+	*out = *in
+	if in.ReadyTime != nil {
+		in, out := &in.ReadyTime, &out.ReadyTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]RegisteredBackend, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = make([]RegisteredBackend, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deregister != nil {
+		in, out := &in.Deregister, &out.Deregister
+		*out = new(RegistrationRequest)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrationStatus.
+func (in *RegistrationStatus) DeepCopy() *RegistrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartBackoffPolicy) DeepCopyInto(out *RestartBackoffPolicy) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDelaySeconds != nil {
+		in, out := &in.MaxDelaySeconds, &out.MaxDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WindowSeconds != nil {
+		in, out := &in.WindowSeconds, &out.WindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartBackoffPolicy.
+func (in *RestartBackoffPolicy) DeepCopy() *RestartBackoffPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartBackoffPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleBasedGroup) DeepCopyInto(out *RoleBasedGroup) {
 	*out = *in
@@ -451,16 +826,56 @@ func (in *RoleBasedGroupSet) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RoleBasedGroupSetList) DeepCopyInto(out *RoleBasedGroupSetList) {
+func (in *RoleBasedGroupSetDrainPolicy) DeepCopyInto(out *RoleBasedGroupSetDrainPolicy) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]RoleBasedGroupSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RoutingLabels != nil {
+		in, out := &in.RoutingLabels, &out.RoutingLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetDrainPolicy.
+func (in *RoleBasedGroupSetDrainPolicy) DeepCopy() *RoleBasedGroupSetDrainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetDrainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetIndexStatus) DeepCopyInto(out *RoleBasedGroupSetIndexStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetIndexStatus.
+func (in *RoleBasedGroupSetIndexStatus) DeepCopy() *RoleBasedGroupSetIndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetIndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetList) DeepCopyInto(out *RoleBasedGroupSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RoleBasedGroupSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -482,6 +897,129 @@ func (in *RoleBasedGroupSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetOverride) DeepCopyInto(out *RoleBasedGroupSetOverride) {
+	*out = *in
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.IndexRange != nil {
+		in, out := &in.IndexRange, &out.IndexRange
+		*out = new(IndexRange)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Patch.DeepCopyInto(&out.Patch)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetOverride.
+func (in *RoleBasedGroupSetOverride) DeepCopy() *RoleBasedGroupSetOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetRevisionStatus) DeepCopyInto(out *RoleBasedGroupSetRevisionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetRevisionStatus.
+func (in *RoleBasedGroupSetRevisionStatus) DeepCopy() *RoleBasedGroupSetRevisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetRevisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetRoleStatus) DeepCopyInto(out *RoleBasedGroupSetRoleStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetRoleStatus.
+func (in *RoleBasedGroupSetRoleStatus) DeepCopy() *RoleBasedGroupSetRoleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetRoleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetRollingUpdate) DeepCopyInto(out *RoleBasedGroupSetRollingUpdate) {
+	*out = *in
+	out.MaxUnavailable = in.MaxUnavailable
+	out.MaxSurge = in.MaxSurge
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetRollingUpdate.
+func (in *RoleBasedGroupSetRollingUpdate) DeepCopy() *RoleBasedGroupSetRollingUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetRollingUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetRolloutStrategy) DeepCopyInto(out *RoleBasedGroupSetRolloutStrategy) {
+	*out = *in
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RoleBasedGroupSetRollingUpdate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetRolloutStrategy.
+func (in *RoleBasedGroupSetRolloutStrategy) DeepCopy() *RoleBasedGroupSetRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetScaleStrategy) DeepCopyInto(out *RoleBasedGroupSetScaleStrategy) {
+	*out = *in
+	if in.IndicesToDelete != nil {
+		in, out := &in.IndicesToDelete, &out.IndicesToDelete
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(RoleBasedGroupSetDrainPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetScaleStrategy.
+func (in *RoleBasedGroupSetScaleStrategy) DeepCopy() *RoleBasedGroupSetScaleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetScaleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleBasedGroupSetSpec) DeepCopyInto(out *RoleBasedGroupSetSpec) {
 	*out = *in
@@ -491,6 +1029,38 @@ func (in *RoleBasedGroupSetSpec) DeepCopyInto(out *RoleBasedGroupSetSpec) {
 		**out = **in
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]RoleBasedGroupSetOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(RoleBasedGroupSetTopologySpread)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(RoleBasedGroupSetRolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleStrategy != nil {
+		in, out := &in.ScaleStrategy, &out.ScaleStrategy
+		*out = new(RoleBasedGroupSetScaleStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodGroupPolicy != nil {
+		in, out := &in.PodGroupPolicy, &out.PodGroupPolicy
+		*out = new(PodGroupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScalingAdapter != nil {
+		in, out := &in.ScalingAdapter, &out.ScalingAdapter
+		*out = new(ScalingAdapter)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetSpec.
@@ -506,6 +1076,26 @@ func (in *RoleBasedGroupSetSpec) DeepCopy() *RoleBasedGroupSetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleBasedGroupSetStatus) DeepCopyInto(out *RoleBasedGroupSetStatus) {
 	*out = *in
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]RoleBasedGroupSetRevisionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoleStatuses != nil {
+		in, out := &in.RoleStatuses, &out.RoleStatuses
+		*out = make([]RoleBasedGroupSetRoleStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.IndexStatuses != nil {
+		in, out := &in.IndexStatuses, &out.IndexStatuses
+		*out = make([]RoleBasedGroupSetIndexStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.TopologyDistribution != nil {
+		in, out := &in.TopologyDistribution, &out.TopologyDistribution
+		*out = make([]TopologyDomainStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -525,6 +1115,26 @@ func (in *RoleBasedGroupSetStatus) DeepCopy() *RoleBasedGroupSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleBasedGroupSetTopologySpread) DeepCopyInto(out *RoleBasedGroupSetTopologySpread) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSetTopologySpread.
+func (in *RoleBasedGroupSetTopologySpread) DeepCopy() *RoleBasedGroupSetTopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleBasedGroupSetTopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleBasedGroupSpec) DeepCopyInto(out *RoleBasedGroupSpec) {
 	*out = *in
@@ -540,6 +1150,21 @@ func (in *RoleBasedGroupSpec) DeepCopyInto(out *RoleBasedGroupSpec) {
 		*out = new(PodGroupPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(ExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(DiscoveryPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleBasedGroupSpec.
@@ -565,7 +1190,16 @@ func (in *RoleBasedGroupStatus) DeepCopyInto(out *RoleBasedGroupStatus) {
 	if in.RoleStatuses != nil {
 		in, out := &in.RoleStatuses, &out.RoleStatuses
 		*out = make([]RoleStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Registrations != nil {
+		in, out := &in.Registrations, &out.Registrations
+		*out = make([]RegistrationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -579,6 +1213,84 @@ func (in *RoleBasedGroupStatus) DeepCopy() *RoleBasedGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleDiscoveryConfig) DeepCopyInto(out *RoleDiscoveryConfig) {
+	*out = *in
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(DiscoveryTemplate)
+		**out = **in
+	}
+	if in.SetConfig != nil {
+		in, out := &in.SetConfig, &out.SetConfig
+		*out = new(SetDiscoveryConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleDiscoveryConfig.
+func (in *RoleDiscoveryConfig) DeepCopy() *RoleDiscoveryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleDiscoveryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleRegistration) DeepCopyInto(out *RoleRegistration) {
+	*out = *in
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]RegistrationBackend, len(*in))
+		copy(*out, *in)
+	}
+	out.Register = in.Register
+	if in.Deregister != nil {
+		in, out := &in.Deregister, &out.Deregister
+		*out = new(RegistrationRequest)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleRegistration.
+func (in *RoleRegistration) DeepCopy() *RoleRegistration {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleRegistration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleService) DeepCopyInto(out *RoleService) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SessionAffinityConfig != nil {
+		in, out := &in.SessionAffinityConfig, &out.SessionAffinityConfig
+		*out = new(v1.SessionAffinityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleService.
+func (in *RoleService) DeepCopy() *RoleService {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 	*out = *in
@@ -592,11 +1304,43 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 		*out = new(RolloutStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestartBackoff != nil {
+		in, out := &in.RestartBackoff, &out.RestartBackoff
+		*out = new(RestartBackoffPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceSize != nil {
+		in, out := &in.InstanceSize, &out.InstanceSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureDetection != nil {
+		in, out := &in.FailureDetection, &out.FailureDetection
+		*out = new(FailureDetectionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Dependencies != nil {
 		in, out := &in.Dependencies, &out.Dependencies
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]Dependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartupBarrier != nil {
+		in, out := &in.StartupBarrier, &out.StartupBarrier
+		*out = new(StartupBarrier)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Workload = in.Workload
 	in.Template.DeepCopyInto(&out.Template)
 	in.LeaderWorkerSet.DeepCopyInto(&out.LeaderWorkerSet)
@@ -607,6 +1351,26 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(RoleService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(RoleDiscoveryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Injection != nil {
+		in, out := &in.Injection, &out.Injection
+		*out = new(InjectionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Registration != nil {
+		in, out := &in.Registration, &out.Registration
+		*out = new(RoleRegistration)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EngineRuntimes != nil {
 		in, out := &in.EngineRuntimes, &out.EngineRuntimes
 		*out = make([]EngineRuntime, len(*in))
@@ -634,6 +1398,10 @@ func (in *RoleSpec) DeepCopy() *RoleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleStatus) DeepCopyInto(out *RoleStatus) {
 	*out = *in
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleStatus.
@@ -698,6 +1466,56 @@ func (in *ScalingAdapter) DeepCopy() *ScalingAdapter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SetDiscoveryConfig) DeepCopyInto(out *SetDiscoveryConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SetDiscoveryConfig.
+func (in *SetDiscoveryConfig) DeepCopy() *SetDiscoveryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SetDiscoveryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupBarrier) DeepCopyInto(out *StartupBarrier) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupBarrier.
+func (in *StartupBarrier) DeepCopy() *StartupBarrier {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupBarrier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyDomainStatus) DeepCopyInto(out *TopologyDomainStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyDomainStatus.
+func (in *TopologyDomainStatus) DeepCopy() *TopologyDomainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyDomainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSpec) DeepCopyInto(out *WorkloadSpec) {
 	*out = *in
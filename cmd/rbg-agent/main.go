@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// rbg-agent is an optional sidecar serving the discovery config of a RoleBasedGroup to the containers of the pod on
+// a localhost HTTP endpoint, and running hooks when the membership changes, so that engines can follow scale
+// events without restarts. With --wait-for-roles it runs as the startup barrier init container instead, exiting
+// once the roles are ready.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/agent"
+	"sigs.k8s.io/rbgs/version"
+)
+
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+func main() {
+	var (
+		source          string
+		configPath      string
+		pollInterval    time.Duration
+		configMapName   string
+		configMapKey    string
+		namespace       string
+		listenAddress   string
+		onChangeExec    string
+		onChangeHTTPURL string
+		hookTimeout     time.Duration
+		runHooksOnStart bool
+		waitForRoles    string
+		group           string
+		waitTimeout     time.Duration
+		waitInterval    time.Duration
+	)
+	flag.StringVar(&source, "source", "file",
+		"Where the discovery config is read from: file, the mounted config, or configmap, the API server.")
+	flag.StringVar(&configPath, "config-path", "/etc/rbg/config.yaml", "The path of the mounted discovery config.")
+	flag.DurationVar(&pollInterval, "poll-interval", 2*time.Second, "The interval the mounted config is read at.")
+	flag.StringVar(&configMapName, "configmap-name", "",
+		"The name of the discovery ConfigMap. Default to $GROUP_NAME-$ROLE_NAME.")
+	flag.StringVar(&configMapKey, "configmap-key", "config.yaml", "The key of the config in the discovery ConfigMap.")
+	flag.StringVar(&namespace, "namespace", "",
+		"The namespace of the discovery ConfigMap or the RoleBasedGroup. Default to the pod namespace.")
+	flag.StringVar(&listenAddress, "listen-address", "127.0.0.1:8089", "The address the discovery API listens on.")
+	flag.StringVar(&onChangeExec, "on-change-exec", "", "The shell command run when the config changes.")
+	flag.StringVar(&onChangeHTTPURL, "on-change-http-url", "", "The URL the config is posted to when it changes.")
+	flag.DurationVar(&hookTimeout, "hook-timeout", 30*time.Second, "The timeout of a hook.")
+	flag.BoolVar(&runHooksOnStart, "run-hooks-on-start", false, "Run the hooks for the config loaded at start.")
+	flag.StringVar(&waitForRoles, "wait-for-roles", "",
+		"Wait until the comma-separated roles, each as <role> or <role>=<threshold>, are ready and exit, "+
+			"instead of serving the discovery API.")
+	flag.StringVar(&group, "group", "", "The RoleBasedGroup of the roles waited for. Default to $GROUP_NAME.")
+	flag.DurationVar(&waitTimeout, "wait-timeout", 0, "Fail if the roles are not ready in time. 0 waits forever.")
+	flag.DurationVar(&waitInterval, "wait-interval", 5*time.Second, "The interval the roles are checked at.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	logger := ctrl.Log.WithName("rbg-agent")
+	logger.Info(fmt.Sprintf("RBG Agent Version: %s, git commit: %s, build date: %s",
+		version.Version, version.GitCommit, version.BuildDate))
+
+	if waitForRoles != "" {
+		if err := waitForReady(logger, waitForRoles, group, namespace, waitTimeout, waitInterval); err != nil {
+			logger.Error(err, "Roles are not ready")
+			os.Exit(1)
+		}
+		return
+	}
+
+	src, err := newSource(source, configPath, pollInterval, configMapName, configMapKey, namespace)
+	if err != nil {
+		logger.Error(err, "Invalid source")
+		os.Exit(1)
+	}
+	var hooks []agent.Hook
+	if onChangeExec != "" {
+		hooks = append(hooks, &agent.ExecHook{Command: onChangeExec})
+	}
+	if onChangeHTTPURL != "" {
+		hooks = append(hooks, &agent.HTTPHook{URL: onChangeHTTPURL, Client: http.DefaultClient})
+	}
+
+	ctx := ctrl.LoggerInto(ctrl.SetupSignalHandler(), logger)
+	store := agent.NewStore()
+	go func() {
+		if err := src.Run(ctx, store); err != nil {
+			logger.Error(err, "Failed to run source")
+			os.Exit(1)
+		}
+	}()
+	if len(hooks) > 0 {
+		runner := &agent.HookRunner{Hooks: hooks, Timeout: hookTimeout, RunOnStart: runHooksOnStart}
+		go runner.Run(ctx, store)
+	}
+
+	server := &http.Server{Addr: listenAddress, Handler: agent.NewServer(store), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	logger.Info("Serving discovery API", "address", listenAddress, "source", source)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "Failed to serve discovery API")
+		os.Exit(1)
+	}
+}
+
+func newSource(
+	source, configPath string, pollInterval time.Duration, configMapName, configMapKey, namespace string,
+) (agent.Source, error) {
+	switch source {
+	case "file":
+		return &agent.FileSource{Path: configPath, Interval: pollInterval}, nil
+	case "configmap":
+		if configMapName == "" {
+			group, role := os.Getenv("GROUP_NAME"), os.Getenv("ROLE_NAME")
+			if group == "" || role == "" {
+				return nil, errors.New("--configmap-name is required if GROUP_NAME or ROLE_NAME is not set")
+			}
+			configMapName = fmt.Sprintf("%s-%s", group, role)
+		}
+		namespace, err := podNamespace(namespace)
+		if err != nil {
+			return nil, err
+		}
+		client, err := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
+		if err != nil {
+			return nil, err
+		}
+		return &agent.ConfigMapSource{Client: client, Namespace: namespace, Name: configMapName, Key: configMapKey}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+}
+
+// waitForReady runs the startup barrier, blocking until the roles of the group are ready.
+func waitForReady(
+	logger logr.Logger, roles, group, namespace string, timeout, interval time.Duration,
+) error {
+	if group == "" {
+		if group = os.Getenv("GROUP_NAME"); group == "" {
+			return errors.New("--group is required if GROUP_NAME is not set")
+		}
+	}
+	namespace, err := podNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	scheme := runtime.NewScheme()
+	if err := workloadsv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	ctx := ctrl.LoggerInto(ctrl.SetupSignalHandler(), logger)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	barrier := &agent.Barrier{
+		Client:    c,
+		Namespace: namespace,
+		Group:     group,
+		Roles:     strings.Split(roles, ","),
+		Interval:  interval,
+	}
+	return barrier.Wait(ctx)
+}
+
+// podNamespace returns namespace, or the namespace of the pod the agent runs in if it is empty.
+func podNamespace(namespace string) (string, error) {
+	if namespace != "" {
+		return namespace, nil
+	}
+	data, err := os.ReadFile(namespaceFile)
+	if err != nil {
+		return "", fmt.Errorf("--namespace is required out of a pod: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
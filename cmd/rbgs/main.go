@@ -92,6 +92,7 @@ func main() {
 		// Controller runtime options
 		maxConcurrentReconciles int
 		cacheSyncTimeout        time.Duration
+		restartCoalescingWindow time.Duration
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -114,6 +115,9 @@ func main() {
 	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 10,
 		"The number of worker threads used by the the RBGS controller.")
 	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", 120*time.Second, "Informer cache sync timeout.")
+	flag.DurationVar(&restartCoalescingWindow, "restart-coalescing-window", 5*time.Second,
+		"How long to wait after the first pod failure of a RoleBasedGroup before restarting it, "+
+			"so that a burst of pod failures results in one restart.")
 
 	flag.Parse()
 	opts := zap.Options{
@@ -258,7 +262,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	podReconciler := workloadscontroller.NewPodReconciler(mgr)
+	podReconciler := workloadscontroller.NewPodReconciler(mgr, restartCoalescingWindow)
 	if err = podReconciler.SetupWithManager(mgr, options); err != nil {
 		setupLog.Error(err, "unable to create pod controller", "controller", "Pod")
 		os.Exit(1)
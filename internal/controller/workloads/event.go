@@ -2,14 +2,18 @@ package workloads
 
 // rbg-controller events
 const (
-	FailedGetRBG               = "FailedGetRBG"
-	InvalidRoleDependency      = "InvalidRoleDependency"
-	FailedCheckRoleDependency  = "FailedCheckRoleDependency"
-	FailedReconcileWorkload    = "FailedReconcileWorkload"
-	FailedCreateScalingAdapter = "FailedCreateScalingAdapter"
-	Succeed                    = "Succeed"
-	FailedUpdateStatus         = "FailedUpdateStatus"
-	FailedCreatePodGroup       = "FailedCreatePodGroup"
+	FailedGetRBG                = "FailedGetRBG"
+	InvalidRoleDependency       = "InvalidRoleDependency"
+	FailedCheckRoleDependency   = "FailedCheckRoleDependency"
+	FailedReconcileWorkload     = "FailedReconcileWorkload"
+	FailedCreateScalingAdapter  = "FailedCreateScalingAdapter"
+	Succeed                     = "Succeed"
+	FailedUpdateStatus          = "FailedUpdateStatus"
+	FailedCreatePodGroup        = "FailedCreatePodGroup"
+	FailedReconcilePDB          = "FailedReconcilePDB"
+	FailedReconcileService      = "FailedReconcileService"
+	FailedReconcileExpose       = "FailedReconcileExpose"
+	FailedReconcileRegistration = "FailedReconcileRegistration"
 )
 
 // rbg-scaling-adapter events
@@ -19,4 +23,18 @@ const (
 	FailedScale                = "FailedScale"
 	FailedGetRBGRole           = "FailedGetRBGRole"
 	FailedGetRBGScalingAdapter = "FailedGetRBGScalingAdapter"
+	FailedGetRBGSet            = "FailedGetRBGSet"
+)
+
+// pod-controller events
+const (
+	RestartBudgetExceeded = "RestartBudgetExceeded"
+	RestartTriggered      = "RestartTriggered"
+)
+
+// rbgset-controller events
+const (
+	RollingUpdateRBG         = "RollingUpdateRBG"
+	DrainingRBG              = "DrainingRBG"
+	FailedReconcileDiscovery = "FailedReconcileDiscovery"
 )
@@ -2,13 +2,21 @@ package workloads
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,16 +32,51 @@ import (
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
+const (
+	defaultRestartInitialDelaySeconds int32 = 10
+	defaultRestartMaxDelaySeconds     int32 = 300
+	defaultRestartWindowSeconds       int32 = 3600
+)
+
 // PodReconciler reconciles a Pod object owned by RBG
 type PodReconciler struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+
+	// restartCoalescingWindow is how long the controller waits after the first pod failure of a rbg before
+	// restarting it, so that a burst of pod failures results in one restart.
+	restartCoalescingWindow time.Duration
+
+	// The records below are kept in memory only and lost when the controller restarts or fails over. The pod
+	// failures in a pending coalescing window are then handled once the failures are observed again, and the
+	// recreation times fall back to the last restart times in the rbg status.
+	triggerLock sync.Mutex
+	// restartTriggers records the pod failures which triggered the restart of a rbg.
+	// Key: types.NamespacedName of the rbg, Value: pod failures in the order they were observed
+	restartTriggers map[types.NamespacedName][]restartTrigger
+	// recreatedAt records when the roles of a rbg were recreated by the last restart. Failures of pods created
+	// before are caused by the restart itself.
+	// Key: types.NamespacedName of the rbg, Value: role name -> recreation time
+	recreatedAt map[types.NamespacedName]map[string]time.Time
+}
+
+// restartTrigger is the pod failure that triggered a rbg restart.
+type restartTrigger struct {
+	role   string
+	pod    string
+	reason string
+	// nodeFailed is true if the pod runs on a failed node, the pod has to be force deleted to be recreated.
+	nodeFailed bool
+	observedAt time.Time
 }
 
-func NewPodReconciler(mgr ctrl.Manager) *PodReconciler {
+func NewPodReconciler(mgr ctrl.Manager, restartCoalescingWindow time.Duration) *PodReconciler {
 	return &PodReconciler{
-		client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
+		client:                  mgr.GetClient(),
+		scheme:                  mgr.GetScheme(),
+		recorder:                mgr.GetEventRecorderFor("pod-controller"),
+		restartCoalescingWindow: restartCoalescingWindow,
 	}
 }
 
@@ -43,34 +86,310 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		Name:      req.Name,
 		Namespace: req.Namespace,
 	}, &rbg); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forgetRBG(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 	logger := log.FromContext(ctx).WithValues("rbg", klog.KObj(&rbg))
 
-	if err := r.restartRBG(ctx, &rbg); err != nil {
+	if rbgFailed(&rbg) {
+		logger.Info("rbg has used up its restart budget, skip restarting")
+		r.removeRestartTriggers(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// pods on NotReady nodes and pending pods are not failed until their grace period expires,
+	// check them again when the earliest grace period expires.
+	requeueAfter, err := r.detectPodFailures(ctx, &rbg)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result, err := r.handleRestartTriggers(ctx, &rbg)
+	if err == nil && !result.Requeue && requeueAfter > 0 &&
+		(result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter) {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, err
+}
+
+// handleRestartTriggers applies the restart policy of the roles whose pods failed.
+func (r *PodReconciler) handleRestartTriggers(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("rbg", klog.KObj(rbg))
+	key := types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}
+
+	triggers := r.getRestartTriggers(key)
+	if len(triggers) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// wait for the coalescing window, so that the failures of a burst are handled by one restart
+	now := time.Now()
+	if wait := triggers[0].observedAt.Add(r.restartCoalescingWindow).Sub(now); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	// handle the failures of one role at a time, the failures of roles not recreated by this restart
+	// are handled in the next round
+	trigger := triggers[0]
+	role, err := rbg.GetRole(trigger.role)
+	if err != nil {
+		return ctrl.Result{Requeue: r.removeRestartTriggers(key, trigger.role) > 0}, nil
+	}
+
+	restarts := restartsInWindow(rbg, role, now)
+	if restartBudgetExceeded(role, restarts) {
+		r.removeRestartTriggers(key)
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, RestartBudgetExceeded,
+			"Role %s restarted %d times within %ds, stop restarting rbg", role.Name, restarts,
+			ptr.Deref(role.RestartBackoff.WindowSeconds, defaultRestartWindowSeconds))
+		return ctrl.Result{}, r.setFailedCondition(ctx, rbg, role, restarts)
+	}
+
+	status, _ := rbg.GetRoleStatus(role.Name)
+	if delay := restartBackoffDelay(role, status, restarts, now); delay > 0 {
+		logger.Info("Restart is backing off", "role", role.Name, "restarts", restarts, "delay", delay)
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	// the roles recreated by this restart, nil means all the roles of rbg
+	var roles []*workloadsv1alpha1.RoleSpec
+	var roleNames []string
+	if role.RestartPolicy == workloadsv1alpha1.RecreateRoleInstanceOnPodRestart {
+		roleNames = []string{role.Name}
+	} else {
+		if roles, err = r.rolesToRecreate(ctx, rbg, role); err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, recreated := range roles {
+			roleNames = append(roleNames, recreated.Name)
+		}
+	}
+
+	// all the pod failures handled by this restart
+	var handled []restartTrigger
+	for _, t := range triggers {
+		if roleNames == nil || utils.ContainsString(roleNames, t.role) {
+			handled = append(handled, t)
+		}
+	}
+	recordRoleRestart(rbg, role, coalescedRestartReason(handled), restarts, now)
+	r.recorder.Eventf(rbg, corev1.EventTypeNormal, RestartTriggered, "%s, triggered by pods %s",
+		restartTarget(role, roleNames), strings.Join(triggerPods(handled), ", "))
+
+	// pods on failed nodes can not be deleted gracefully, which blocks the recreation of sts pods
+	if err := r.forceDeleteNodeFailedPods(ctx, rbg.Namespace, handled); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if role.RestartPolicy == workloadsv1alpha1.RecreateRoleInstanceOnPodRestart {
+		if err := r.patchRestartStatus(ctx, rbg); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.recreateRoleInstances(ctx, rbg, role, triggerPods(handled)); err != nil {
+			logger.Error(err, "recreate role instances error", "role", role.Name)
+			return ctrl.Result{}, err
+		}
+	} else if err := r.restartRBG(ctx, rbg, roles); err != nil {
 		logger.Error(err, fmt.Sprintf("restartRBG error, err: %+v", err))
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{Requeue: r.removeRestartTriggers(key, roleNames...) > 0}, nil
+}
+
+// addRestartTrigger records a pod failure of the rbg. Repeated events of the same pod are ignored.
+func (r *PodReconciler) addRestartTrigger(key types.NamespacedName, trigger restartTrigger) {
+	r.triggerLock.Lock()
+	defer r.triggerLock.Unlock()
+
+	if r.restartTriggers == nil {
+		r.restartTriggers = make(map[types.NamespacedName][]restartTrigger)
+	}
+	for _, t := range r.restartTriggers[key] {
+		if t.pod == trigger.pod {
+			return
+		}
+	}
+	if trigger.observedAt.IsZero() {
+		trigger.observedAt = time.Now()
+	}
+	r.restartTriggers[key] = append(r.restartTriggers[key], trigger)
+}
+
+func (r *PodReconciler) getRestartTriggers(key types.NamespacedName) []restartTrigger {
+	r.triggerLock.Lock()
+	defer r.triggerLock.Unlock()
+
+	return append([]restartTrigger(nil), r.restartTriggers[key]...)
+}
+
+// removeRestartTriggers removes the pod failures of the roles, or all the pod failures of the rbg if no role is
+// given. It returns the number of the remaining pod failures.
+func (r *PodReconciler) removeRestartTriggers(key types.NamespacedName, roles ...string) int {
+	r.triggerLock.Lock()
+	defer r.triggerLock.Unlock()
+
+	var remaining []restartTrigger
+	for _, t := range r.restartTriggers[key] {
+		if len(roles) > 0 && !utils.ContainsString(roles, t.role) {
+			remaining = append(remaining, t)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(r.restartTriggers, key)
+		return 0
+	}
+	r.restartTriggers[key] = remaining
+	return len(remaining)
+}
+
+// markRolesRecreated records the time the roles of the rbg are recreated.
+func (r *PodReconciler) markRolesRecreated(key types.NamespacedName, roles []string, now time.Time) {
+	r.triggerLock.Lock()
+	defer r.triggerLock.Unlock()
+
+	if r.recreatedAt == nil {
+		r.recreatedAt = make(map[types.NamespacedName]map[string]time.Time)
+	}
+	if r.recreatedAt[key] == nil {
+		r.recreatedAt[key] = make(map[string]time.Time)
+	}
+	for _, role := range roles {
+		r.recreatedAt[key][role] = now
+	}
+}
+
+// createdBeforeRecreation returns true if the pod was created before its role was recreated by the last restart.
+// Without the recreation time in memory, e.g. after the controller restarted, the last restart recorded in the rbg
+// status of the role itself or of a role recreating the whole rbg is used instead.
+func (r *PodReconciler) createdBeforeRecreation(rbg *workloadsv1alpha1.RoleBasedGroup, role string, pod *corev1.Pod) bool {
+	r.triggerLock.Lock()
+	recreatedAt, ok := r.recreatedAt[types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}][role]
+	r.triggerLock.Unlock()
+
+	if !ok {
+		for _, status := range rbg.Status.RoleStatuses {
+			if status.LastRestartTime == nil || status.LastRestartTime.Time.Before(recreatedAt) {
+				continue
+			}
+			spec, err := rbg.GetRole(status.Name)
+			if err != nil || spec.RestartPolicy == workloadsv1alpha1.RecreateRoleInstanceOnPodRestart {
+				continue
+			}
+			if status.Name == role || spec.RestartPolicy == workloadsv1alpha1.RecreateRBGOnPodRestart {
+				recreatedAt, ok = status.LastRestartTime.Time, true
+			}
+		}
+	}
+	// creationTimestamp is in seconds
+	return ok && pod.CreationTimestamp.Time.Before(recreatedAt.Truncate(time.Second))
+}
+
+// forgetRBG drops all the records of a deleted rbg.
+func (r *PodReconciler) forgetRBG(key types.NamespacedName) {
+	r.triggerLock.Lock()
+	defer r.triggerLock.Unlock()
+
+	delete(r.restartTriggers, key)
+	delete(r.recreatedAt, key)
 }
 
-func (r *PodReconciler) restartRBG(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+// recreateRoleInstances deletes all the pods of the role instances which the given pods belong to, the workload
+// controller then creates the pods of these instances again. Other instances of the role are not impacted.
+func (r *PodReconciler) recreateRoleInstances(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, podNames []string,
+) error {
 	logger := log.FromContext(ctx)
-	logger.Info("Recreating RoleBasedGroup")
 
-	// 1. update rbg status
-	if err := r.setRestartCondition(ctx, rbg, false); err != nil {
+	// instance key -> name of the pod which triggered the recreation
+	instances := make(map[string]string)
+	for _, name := range podNames {
+		instances[instanceKey(rbg, role, name)] = name
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels(rbg.GetCommonLabelsFromRole(role))); err != nil {
 		return err
 	}
 
-	// 2. sort role
-	dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
-	sortedRoles, err := dependencyManager.SortRoles(ctx, rbg)
-	if err != nil {
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		trigger, ok := instances[instanceKey(rbg, role, pod.Name)]
+		if !ok {
+			continue
+		}
+
+		logger.Info("Recreate role instance, delete pod", "role", role.Name, "pod", pod.Name, "trigger", trigger)
+		if err := r.markAndDeletePod(ctx, pod, trigger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markAndDeletePod marks the pod before deleting it, so that its deletion does not trigger another recreation.
+func (r *PodReconciler) markAndDeletePod(
+	ctx context.Context, pod *corev1.Pod, trigger string, opts ...client.DeleteOption,
+) error {
+	if _, marked := pod.Annotations[workloadsv1alpha1.RecreatingInstanceAnnotationKey]; !marked {
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[workloadsv1alpha1.RecreatingInstanceAnnotationKey] = trigger
+		if err := r.client.Patch(ctx, pod, patch); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return client.IgnoreNotFound(r.client.Delete(ctx, pod, opts...))
+}
+
+// instanceKey returns the key of the role instance which the pod belongs to. A sts instance is a block of
+// role.instanceSize consecutive pod indices, while each pod of other workloads is an instance.
+func instanceKey(rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, podName string) string {
+	if role.Workload.String() == workloadsv1alpha1.StatefulSetWorkloadType {
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(podName, rbg.GetWorkloadName(role)+"-"))
+		if err == nil {
+			return strconv.Itoa(ordinal / int(ptr.Deref(role.InstanceSize, 1)))
+		}
+	}
+	return podName
+}
+
+// restartRBG recreates the given roles of rbg in dependency order. If roles is nil, all the roles are recreated.
+func (r *PodReconciler) restartRBG(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roles []*workloadsv1alpha1.RoleSpec,
+) error {
+	logger := log.FromContext(ctx)
+
+	// 1. sort role
+	if roles == nil {
+		logger.Info("Recreating RoleBasedGroup")
+		dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
+		sortedRoles, err := dependencyManager.SortRoles(ctx, rbg)
+		if err != nil {
+			return err
+		}
+		roles = sortedRoles
+	}
+	roleNames := make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	logger.Info("Recreating roles of RoleBasedGroup", "roles", roleNames)
+	r.markRolesRecreated(types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, roleNames, time.Now())
+
+	// 2. update rbg status
+	if err := r.setRestartCondition(ctx, rbg, false); err != nil {
 		return err
 	}
-	for _, role := range sortedRoles {
+
+	for _, role := range roles {
 		recon, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
 		if err != nil {
 			return err
@@ -112,11 +431,200 @@ func (r *PodReconciler) setRestartCondition(ctx context.Context, rbg *workloadsv
 	setCondition(rbg, restartCondition)
 
 	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
-		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions))
+		WithStatus(utils.RbgStatus().
+			WithObservedGeneration(rbg.Status.ObservedGeneration).
+			WithRoleStatuses(rbg.Status.RoleStatuses).
+			WithConditions(rbg.Status.Conditions))
+
+	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
+}
+
+func (r *PodReconciler) setFailedCondition(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, restarts int32,
+) error {
+	setCondition(rbg, metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: rbg.Generation,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "RestartBudgetExceeded",
+		Message: fmt.Sprintf("Role %s restarted %d times within %ds",
+			role.Name, restarts, ptr.Deref(role.RestartBackoff.WindowSeconds, defaultRestartWindowSeconds)),
+	})
+
+	return r.patchRestartStatus(ctx, rbg)
+}
+
+// patchRestartStatus patches the restart records and conditions of rbg status.
+func (r *PodReconciler) patchRestartStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
+		WithStatus(utils.RbgStatus().
+			WithObservedGeneration(rbg.Status.ObservedGeneration).
+			WithRoleStatuses(rbg.Status.RoleStatuses).
+			WithConditions(rbg.Status.Conditions))
 
 	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
 }
 
+// rbgFailed returns true if the rbg has used up the restart budget since its spec was last updated.
+func rbgFailed(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	cond := meta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupFailed))
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == rbg.Generation
+}
+
+// resetFailedCondition sets the Failed condition to False once the spec of rbg has been updated after it failed, as
+// the restart budget starts again for the new spec. It reports whether the condition is changed.
+func resetFailedCondition(rbg *workloadsv1alpha1.RoleBasedGroup, now time.Time) bool {
+	cond := meta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupFailed))
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration == rbg.Generation {
+		return false
+	}
+	setCondition(rbg, metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: rbg.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             "SpecUpdated",
+		Message:            "RBG spec updated, restart budget reset",
+	})
+	return true
+}
+
+// restartsInWindow returns the number of restarts the role has triggered within the current budget window.
+// The counter is reset when no restart has happened for a whole window, or when the rbg was failed
+// before its spec got updated, whether or not the Failed condition has been reset yet. The roles without
+// restart backoff have no budget window, their restarts are counted without being reset.
+func restartsInWindow(rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, now time.Time) int32 {
+	status, found := rbg.GetRoleStatus(role.Name)
+	if !found || status.LastRestartTime == nil {
+		return 0
+	}
+	if role.RestartBackoff == nil {
+		return status.RestartCount
+	}
+	if cond := meta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupFailed)); cond != nil {
+		if cond.Status == metav1.ConditionTrue {
+			return 0
+		}
+		// the failed rbg has been reset by a spec update since the last restart
+		if cond.Reason == "SpecUpdated" && status.LastRestartTime.Before(&cond.LastTransitionTime) {
+			return 0
+		}
+	}
+	window := time.Duration(ptr.Deref(role.RestartBackoff.WindowSeconds, defaultRestartWindowSeconds)) * time.Second
+	if now.Sub(status.LastRestartTime.Time) >= window {
+		return 0
+	}
+	return status.RestartCount
+}
+
+func restartBudgetExceeded(role *workloadsv1alpha1.RoleSpec, restarts int32) bool {
+	if role.RestartBackoff == nil || role.RestartBackoff.MaxRestarts == 0 {
+		return false
+	}
+	return restarts >= role.RestartBackoff.MaxRestarts
+}
+
+// restartBackoffDelay returns how long the next restart has to wait. The first restart in a window is
+// performed immediately, the delay of the following ones doubles from InitialDelaySeconds up to MaxDelaySeconds.
+func restartBackoffDelay(
+	role *workloadsv1alpha1.RoleSpec, status workloadsv1alpha1.RoleStatus, restarts int32, now time.Time,
+) time.Duration {
+	if role.RestartBackoff == nil || restarts == 0 || status.LastRestartTime == nil {
+		return 0
+	}
+	delay := time.Duration(ptr.Deref(role.RestartBackoff.InitialDelaySeconds, defaultRestartInitialDelaySeconds)) * time.Second
+	maxDelay := time.Duration(ptr.Deref(role.RestartBackoff.MaxDelaySeconds, defaultRestartMaxDelaySeconds)) * time.Second
+	for i := int32(1); i < restarts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	return status.LastRestartTime.Add(delay).Sub(now)
+}
+
+// recordRoleRestart updates the restart records of the role in rbg status, they are patched together with
+// the restart condition.
+func recordRoleRestart(
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, reason string, restarts int32,
+	now time.Time,
+) {
+	resetFailedCondition(rbg, now)
+
+	for i := range rbg.Status.RoleStatuses {
+		if rbg.Status.RoleStatuses[i].Name == role.Name {
+			rbg.Status.RoleStatuses[i].RestartCount = restarts + 1
+			rbg.Status.RoleStatuses[i].LastRestartTime = ptr.To(metav1.NewTime(now))
+			rbg.Status.RoleStatuses[i].LastRestartReason = reason
+			return
+		}
+	}
+	rbg.Status.RoleStatuses = append(rbg.Status.RoleStatuses, workloadsv1alpha1.RoleStatus{
+		Name:              role.Name,
+		RestartCount:      restarts + 1,
+		LastRestartTime:   ptr.To(metav1.NewTime(now)),
+		LastRestartReason: reason,
+	})
+}
+
+// rolesToRecreate returns the roles to recreate in dependency order when a pod of the role restarts.
+// nil means all the roles of rbg.
+func (r *PodReconciler) rolesToRecreate(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+) ([]*workloadsv1alpha1.RoleSpec, error) {
+	dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
+	switch role.RestartPolicy {
+	case workloadsv1alpha1.RecreateDependentRolesOnPodRestart:
+		return dependencyManager.SortDependentRoles(ctx, rbg, role.Name, true)
+	case workloadsv1alpha1.RecreateDirectDependentRolesOnPodRestart:
+		return dependencyManager.SortDependentRoles(ctx, rbg, role.Name, false)
+	default:
+		return nil, nil
+	}
+}
+
+// coalescedRestartReason describes all the pod failures handled by one restart.
+func coalescedRestartReason(triggers []restartTrigger) string {
+	if len(triggers) == 1 {
+		return triggers[0].reason
+	}
+	reasons := make([]string, 0, len(triggers))
+	for _, t := range triggers {
+		reasons = append(reasons, t.reason)
+	}
+	return fmt.Sprintf("%d pod failures: %s", len(triggers), strings.Join(reasons, "; "))
+}
+
+func triggerPods(triggers []restartTrigger) []string {
+	pods := make([]string, 0, len(triggers))
+	for _, t := range triggers {
+		pods = append(pods, t.pod)
+	}
+	return pods
+}
+
+// restartTarget describes what is recreated by a restart.
+func restartTarget(role *workloadsv1alpha1.RoleSpec, roleNames []string) string {
+	switch {
+	case role.RestartPolicy == workloadsv1alpha1.RecreateRoleInstanceOnPodRestart:
+		return fmt.Sprintf("Recreate instances of role %s", role.Name)
+	case roleNames == nil:
+		return "Recreate rbg"
+	default:
+		return fmt.Sprintf("Recreate roles %s", strings.Join(roleNames, ", "))
+	}
+}
+
+// restartReason describes why the pod triggers a restart.
+func restartReason(pod *corev1.Pod) string {
+	if utils.PodDeleted(pod) {
+		return fmt.Sprintf("PodDeleted: pod %s was deleted", pod.Name)
+	}
+	if utils.PodEvicted(pod) {
+		return fmt.Sprintf("PodEvicted: pod %s was evicted", pod.Name)
+	}
+	return fmt.Sprintf("ContainerRestarted: container of pod %s restarted", pod.Name)
+}
+
 func restartConditionTrue(status workloadsv1alpha1.RoleBasedGroupStatus) bool {
 	for _, cond := range status.Conditions {
 		if cond.Type == string(workloadsv1alpha1.RoleBasedGroupRestartInProgress) {
@@ -152,7 +660,10 @@ func (r *PodReconciler) podToRBG(ctx context.Context, obj client.Object) []recon
 		return []reconcile.Request{}
 	}
 
-	if !utils.ContainerRestarted(pod) && !utils.PodDeleted(pod) {
+	// a pod which is not ready may run on a NotReady node or stay pending, the rbg is checked by
+	// failure detection of its roles.
+	failed := utils.ContainerRestarted(pod) || utils.PodDeleted(pod) || utils.PodEvicted(pod)
+	if !failed && utils.PodRunningAndReady(*pod) {
 		return []reconcile.Request{}
 	}
 
@@ -172,6 +683,11 @@ func (r *PodReconciler) podToRBG(ctx context.Context, obj client.Object) []recon
 		return []reconcile.Request{}
 	}
 
+	if rbgFailed(&rbg) {
+		logger.V(1).Info("rbg has used up its restart budget, skip handle pod restart event")
+		return []reconcile.Request{}
+	}
+
 	roleName := pod.Labels[workloadsv1alpha1.SetRoleLabelKey]
 	if roleName == "" {
 		return []reconcile.Request{}
@@ -182,19 +698,47 @@ func (r *PodReconciler) podToRBG(ctx context.Context, obj client.Object) []recon
 		return []reconcile.Request{}
 	}
 
-	// 1. if RestartPolicy is None, do nothing
-	// 2. if RestartPolicy is RecreateRoleInstanceOnPodRestart, the lws controller will recreate lws. RBG controller does nothing.
-	if curRole.RestartPolicy != workloadsv1alpha1.RecreateRBGOnPodRestart {
+	if !restartHandledByRBG(curRole) {
+		return []reconcile.Request{}
+	}
+	// the pod is deleted by rbg controller to recreate its instance
+	if _, ok := pod.Annotations[workloadsv1alpha1.RecreatingInstanceAnnotationKey]; ok {
 		return []reconcile.Request{}
 	}
 
+	key := types.NamespacedName{Name: rbgName, Namespace: rbg.Namespace}
+	// the pod is deleted by the last restart, which may not be reflected by the restart condition in cache yet
+	if r.createdBeforeRecreation(&rbg, roleName, pod) {
+		logger.V(1).Info("pod is recreated by the last restart, skip handle pod restart event")
+		return []reconcile.Request{}
+	}
+	if !failed {
+		if curRole.FailureDetection == nil {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	}
+
 	// restart rbg
-	return []reconcile.Request{{
-		NamespacedName: types.NamespacedName{
-			Name:      rbgName,
-			Namespace: rbg.Namespace,
-		},
-	}}
+	r.addRestartTrigger(key, restartTrigger{role: roleName, pod: pod.Name, reason: restartReason(pod)})
+	return []reconcile.Request{{NamespacedName: key}}
+}
+
+// restartHandledByRBG returns true if the restart policy of the role is performed by rbg controller.
+// 1. if RestartPolicy is None, do nothing
+// 2. if RestartPolicy is RecreateRoleInstanceOnPodRestart, the lws controller will recreate lws. RBG controller
+// recreates the instance of sts & deployment.
+func restartHandledByRBG(role *workloadsv1alpha1.RoleSpec) bool {
+	switch role.RestartPolicy {
+	case workloadsv1alpha1.RecreateRBGOnPodRestart,
+		workloadsv1alpha1.RecreateDependentRolesOnPodRestart,
+		workloadsv1alpha1.RecreateDirectDependentRolesOnPodRestart:
+		return true
+	case workloadsv1alpha1.RecreateRoleInstanceOnPodRestart:
+		return role.Workload.String() != workloadsv1alpha1.LeaderWorkerSetWorkloadType
+	default:
+		return false
+	}
 }
 
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -225,9 +769,16 @@ func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Op
 		},
 	}
 
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(), &corev1.Pod{}, podNodeNameIndexKey, indexPodNodeName,
+	); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		Named("pod-controller").
 		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToRBG), builder.WithPredicates(podPredicate)).
+		Watches(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(r.nodeToRBG), builder.WithPredicates(nodePredicate())).
 		Complete(r)
 }
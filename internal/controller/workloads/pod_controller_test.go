@@ -1,15 +1,19 @@
 package workloads
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -106,9 +110,10 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 	).Obj()
 
 	type args struct {
-		ctx  context.Context
-		obj  corev1.Pod
-		role workloadsv1alpha1.RoleSpec
+		ctx        context.Context
+		obj        corev1.Pod
+		role       workloadsv1alpha1.RoleSpec
+		conditions []metav1.Condition
 	}
 	tests := []struct {
 		name string
@@ -153,8 +158,110 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 					WithRestartPolicy(workloadsv1alpha1.RecreateRoleInstanceOnPodRestart).
 					Obj(),
 			},
+			want: []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "restart-policy",
+						Namespace: "default",
+					},
+				},
+			},
+		},
+		{
+			name: "RecreateRoleInstanceOnPodRestart-lws",
+			args: args{
+				ctx: context.TODO(),
+				obj: pod,
+				role: wrappers.BuildLwsRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateRoleInstanceOnPodRestart).
+					Obj(),
+			},
+			want: []reconcile.Request{},
+		},
+		{
+			name: "RecreateRoleInstanceOnPodRestart-recreating",
+			args: args{
+				ctx: context.TODO(),
+				obj: wrappers.BuildDeletingPod().WithLabels(
+					map[string]string{
+						workloadsv1alpha1.SetRoleLabelKey: "test-role",
+						workloadsv1alpha1.SetNameLabelKey: "restart-policy",
+					},
+				).WithAnnotations(
+					map[string]string{
+						workloadsv1alpha1.RecreatingInstanceAnnotationKey: "restart-policy-test-role-0",
+					},
+				).Obj(),
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateRoleInstanceOnPodRestart).
+					Obj(),
+			},
 			want: []reconcile.Request{},
 		},
+		{
+			name: "RecreateDependentRolesOnPodRestart",
+			args: args{
+				ctx: context.TODO(),
+				obj: pod,
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateDependentRolesOnPodRestart).
+					Obj(),
+			},
+			want: []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "restart-policy",
+						Namespace: "default",
+					},
+				},
+			},
+		},
+		{
+			name: "RestartBudgetExceeded",
+			args: args{
+				ctx: context.TODO(),
+				obj: pod,
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateRBGOnPodRestart).
+					Obj(),
+				conditions: []metav1.Condition{
+					{
+						Type:   string(workloadsv1alpha1.RoleBasedGroupFailed),
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+			want: []reconcile.Request{},
+		},
+		{
+			name: "pod-evicted",
+			args: args{
+				ctx: context.TODO(),
+				obj: func() corev1.Pod {
+					evicted := wrappers.BuildBasicPod().WithLabels(
+						map[string]string{
+							workloadsv1alpha1.SetRoleLabelKey: "test-role",
+							workloadsv1alpha1.SetNameLabelKey: "restart-policy",
+						},
+					).Obj()
+					evicted.Namespace = "default"
+					evicted.Status.Phase = corev1.PodFailed
+					evicted.Status.Reason = "Evicted"
+					return evicted
+				}(),
+				role: wrappers.BuildBasicRole("test-role").
+					WithRestartPolicy(workloadsv1alpha1.RecreateRBGOnPodRestart).
+					Obj(),
+			},
+			want: []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "restart-policy",
+						Namespace: "default",
+					},
+				},
+			},
+		},
 		{
 			name: "pod-running",
 			args: args{
@@ -177,6 +284,7 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 			tt.name, func(t *testing.T) {
 				rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").
 					WithRoles([]workloadsv1alpha1.RoleSpec{tt.args.role}).Obj()
+				rbg.Status.Conditions = tt.args.conditions
 				fclient := fake.NewClientBuilder().WithScheme(schema).WithObjects(&tt.args.obj, rbg).Build()
 
 				r := &PodReconciler{
@@ -190,3 +298,566 @@ func TestPodReconciler_podToRBG(t *testing.T) {
 		)
 	}
 }
+
+func TestRestartBackoffDelay(t *testing.T) {
+	now := time.Now()
+	role := wrappers.BuildBasicRole("test-role").
+		WithRestartBackoff(workloadsv1alpha1.RestartBackoffPolicy{
+			InitialDelaySeconds: ptr.To(int32(10)),
+			MaxDelaySeconds:     ptr.To(int32(60)),
+		}).Obj()
+	lastRestart := workloadsv1alpha1.RoleStatus{
+		Name:            "test-role",
+		LastRestartTime: ptr.To(metav1.NewTime(now)),
+	}
+
+	tests := []struct {
+		name     string
+		role     workloadsv1alpha1.RoleSpec
+		status   workloadsv1alpha1.RoleStatus
+		restarts int32
+		want     time.Duration
+	}{
+		{
+			name:     "no backoff policy",
+			role:     wrappers.BuildBasicRole("test-role").Obj(),
+			status:   lastRestart,
+			restarts: 3,
+			want:     0,
+		},
+		{
+			name:     "first restart is immediate",
+			role:     role,
+			status:   workloadsv1alpha1.RoleStatus{Name: "test-role"},
+			restarts: 0,
+			want:     0,
+		},
+		{
+			name:     "second restart waits initial delay",
+			role:     role,
+			status:   lastRestart,
+			restarts: 1,
+			want:     10 * time.Second,
+		},
+		{
+			name:     "delay doubles",
+			role:     role,
+			status:   lastRestart,
+			restarts: 3,
+			want:     40 * time.Second,
+		},
+		{
+			name:     "delay is capped",
+			role:     role,
+			status:   lastRestart,
+			restarts: 10,
+			want:     60 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restartBackoffDelay(&tt.role, tt.status, tt.restarts, now); got != tt.want {
+				t.Errorf("restartBackoffDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestartsInWindow(t *testing.T) {
+	now := time.Now()
+	role := wrappers.BuildBasicRole("test-role").
+		WithRestartBackoff(workloadsv1alpha1.RestartBackoffPolicy{
+			MaxRestarts:   3,
+			WindowSeconds: ptr.To(int32(600)),
+		}).Obj()
+
+	tests := []struct {
+		name         string
+		lastRestart  time.Time
+		conditions   []metav1.Condition
+		want         int32
+		wantExceeded bool
+	}{
+		{
+			name:         "restarts within window",
+			lastRestart:  now.Add(-time.Minute),
+			want:         3,
+			wantExceeded: true,
+		},
+		{
+			name:         "window expired",
+			lastRestart:  now.Add(-time.Hour),
+			want:         0,
+			wantExceeded: false,
+		},
+		{
+			name:        "failed before spec updated",
+			lastRestart: now.Add(-time.Minute),
+			conditions: []metav1.Condition{
+				{
+					Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: 1,
+				},
+			},
+			want:         0,
+			wantExceeded: false,
+		},
+		{
+			name:        "failed condition reset by spec update",
+			lastRestart: now.Add(-time.Minute),
+			conditions: []metav1.Condition{
+				{
+					Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 2,
+					LastTransitionTime: metav1.NewTime(now.Add(-time.Second)),
+					Reason:             "SpecUpdated",
+				},
+			},
+			want:         0,
+			wantExceeded: false,
+		},
+		{
+			name:        "restarts after the failed condition is reset",
+			lastRestart: now.Add(-time.Second),
+			conditions: []metav1.Condition{
+				{
+					Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 2,
+					LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+					Reason:             "SpecUpdated",
+				},
+			},
+			want:         3,
+			wantExceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").
+				WithRoles([]workloadsv1alpha1.RoleSpec{role}).Obj()
+			rbg.Generation = 2
+			rbg.Status.Conditions = tt.conditions
+			rbg.Status.RoleStatuses = []workloadsv1alpha1.RoleStatus{
+				{
+					Name:            "test-role",
+					RestartCount:    3,
+					LastRestartTime: ptr.To(metav1.NewTime(tt.lastRestart)),
+				},
+			}
+
+			got := restartsInWindow(rbg, &role, now)
+			if got != tt.want {
+				t.Errorf("restartsInWindow() = %v, want %v", got, tt.want)
+			}
+			if exceeded := restartBudgetExceeded(&role, got); exceeded != tt.wantExceeded {
+				t.Errorf("restartBudgetExceeded() = %v, want %v", exceeded, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func TestRecordRoleRestart(t *testing.T) {
+	now := time.Now()
+	role := wrappers.BuildBasicRole("test-role").Obj()
+	rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").Obj()
+	// failed before the spec got updated
+	rbg.Generation = 2
+	rbg.Status.Conditions = []metav1.Condition{
+		{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 1,
+		},
+	}
+
+	recordRoleRestart(rbg, &role, "PodDeleted", 0, now)
+	recordRoleRestart(rbg, &role, "ContainerRestarted", 1, now)
+
+	status, found := rbg.GetRoleStatus("test-role")
+	if !found {
+		t.Fatalf("role status not found")
+	}
+	if status.RestartCount != 2 || status.LastRestartReason != "ContainerRestarted" {
+		t.Errorf("recordRoleRestart() status = %+v", status)
+	}
+	if meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupFailed)) {
+		t.Errorf("recordRoleRestart() should reset Failed condition")
+	}
+}
+
+func TestRecordRoleRestartWithoutBackoff(t *testing.T) {
+	now := time.Now()
+	role := wrappers.BuildBasicRole("test-role").Obj()
+	rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").Obj()
+
+	recordRoleRestart(rbg, &role, "PodDeleted", restartsInWindow(rbg, &role, now), now)
+	later := now.Add(time.Hour)
+	recordRoleRestart(rbg, &role, "PodDeleted", restartsInWindow(rbg, &role, later), later)
+
+	status, found := rbg.GetRoleStatus("test-role")
+	if !found {
+		t.Fatalf("role status not found")
+	}
+	if status.RestartCount != 2 {
+		t.Errorf("recordRoleRestart() restartCount = %v, want 2", status.RestartCount)
+	}
+}
+
+func TestResetFailedCondition(t *testing.T) {
+	now := time.Now()
+	rbg := wrappers.BuildBasicRoleBasedGroup("restart-policy", "default").Obj()
+	rbg.Generation = 1
+	rbg.Status.Conditions = []metav1.Condition{
+		{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupFailed),
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 1,
+			Reason:             "RestartBudgetExceeded",
+		},
+	}
+
+	if resetFailedCondition(rbg, now) {
+		t.Errorf("resetFailedCondition() reset the condition of the current generation")
+	}
+
+	rbg.Generation = 2
+	if !resetFailedCondition(rbg, now) {
+		t.Errorf("resetFailedCondition() did not reset the condition after the spec update")
+	}
+	cond := meta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupFailed))
+	if cond.Status != metav1.ConditionFalse || cond.Reason != "SpecUpdated" || cond.ObservedGeneration != 2 {
+		t.Errorf("resetFailedCondition() condition = %+v", cond)
+	}
+	if resetFailedCondition(rbg, now) {
+		t.Errorf("resetFailedCondition() reset the condition twice")
+	}
+}
+
+func TestInstanceKey(t *testing.T) {
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").Obj()
+	stsRole := wrappers.BuildBasicRole("worker").WithInstanceSize(4).Obj()
+	deployRole := wrappers.BuildBasicRole("router").WithWorkload(workloadsv1alpha1.DeploymentWorkloadType).Obj()
+
+	tests := []struct {
+		name    string
+		role    workloadsv1alpha1.RoleSpec
+		podName string
+		want    string
+	}{
+		{
+			name:    "first pod of instance",
+			role:    stsRole,
+			podName: "test-rbg-worker-4",
+			want:    "1",
+		},
+		{
+			name:    "last pod of instance",
+			role:    stsRole,
+			podName: "test-rbg-worker-7",
+			want:    "1",
+		},
+		{
+			name:    "default instance size",
+			role:    wrappers.BuildBasicRole("worker").Obj(),
+			podName: "test-rbg-worker-7",
+			want:    "7",
+		},
+		{
+			name:    "deployment pod",
+			role:    deployRole,
+			podName: "test-rbg-router-5d8f7c9b4-x2x7z",
+			want:    "test-rbg-router-5d8f7c9b4-x2x7z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceKey(rbg, &tt.role, tt.podName); got != tt.want {
+				t.Errorf("instanceKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodReconciler_recreateRoleInstances(t *testing.T) {
+	schema := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(schema)
+	_ = workloadsv1alpha1.AddToScheme(schema)
+
+	role := wrappers.BuildBasicRole("worker").WithReplicas(4).WithInstanceSize(2).Obj()
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").
+		WithRoles([]workloadsv1alpha1.RoleSpec{role}).Obj()
+
+	objs := make([]client.Object, 0)
+	for i := 0; i < 4; i++ {
+		pod := wrappers.BuildBasicPod().WithName(fmt.Sprintf("test-rbg-worker-%d", i)).
+			WithLabels(rbg.GetCommonLabelsFromRole(&role)).Obj()
+		pod.Namespace = "default"
+		objs = append(objs, &pod)
+	}
+	fclient := fake.NewClientBuilder().WithScheme(schema).WithObjects(objs...).Build()
+
+	r := &PodReconciler{
+		client: fclient,
+		scheme: schema,
+	}
+	if err := r.recreateRoleInstances(context.TODO(), rbg, &role, []string{"test-rbg-worker-3"}); err != nil {
+		t.Fatalf("recreateRoleInstances() error = %v", err)
+	}
+
+	podList := &corev1.PodList{}
+	if err := fclient.List(context.TODO(), podList, client.InNamespace("default")); err != nil {
+		t.Fatalf("list pods error = %v", err)
+	}
+	got := make([]string, 0)
+	for _, pod := range podList.Items {
+		got = append(got, pod.Name)
+	}
+	want := []string{"test-rbg-worker-0", "test-rbg-worker-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recreateRoleInstances() remaining pods = %v, want %v", got, want)
+	}
+}
+
+func TestPodReconciler_checkPodFailure(t *testing.T) {
+	schema := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(schema)
+	_ = workloadsv1alpha1.AddToScheme(schema)
+
+	// metav1.Time is serialized in seconds
+	now := time.Now().Truncate(time.Second)
+	buildNode := func(name string, ready corev1.ConditionStatus, since time.Time) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             ready,
+						LastTransitionTime: metav1.NewTime(since),
+					},
+				},
+			},
+		}
+	}
+	buildPod := func(nodeName string, phase corev1.PodPhase, created time.Time) *corev1.Pod {
+		pod := wrappers.BuildBasicPod().Obj()
+		pod.Namespace = "default"
+		pod.Spec.NodeName = nodeName
+		pod.Status.Phase = phase
+		pod.CreationTimestamp = metav1.NewTime(created)
+		return &pod
+	}
+	fclient := fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		buildNode("ready-node", corev1.ConditionTrue, now.Add(-time.Hour)),
+		buildNode("not-ready-node", corev1.ConditionUnknown, now.Add(-time.Minute)),
+	).Build()
+	role := wrappers.BuildBasicRole("test-role").Obj()
+	role.FailureDetection = &workloadsv1alpha1.FailureDetectionPolicy{
+		NodeNotReadyGracePeriodSeconds: ptr.To(int32(120)),
+		PendingGracePeriodSeconds:      ptr.To(int32(600)),
+	}
+
+	tests := []struct {
+		name           string
+		pod            *corev1.Pod
+		gracePeriod    int32
+		wantFailed     bool
+		wantNodeFailed bool
+		wantRemaining  time.Duration
+	}{
+		{
+			name:        "pod on ready node",
+			pod:         buildPod("ready-node", corev1.PodRunning, now.Add(-time.Hour)),
+			gracePeriod: 120,
+		},
+		{
+			name:          "node not ready within grace period",
+			pod:           buildPod("not-ready-node", corev1.PodRunning, now.Add(-time.Hour)),
+			gracePeriod:   120,
+			wantRemaining: time.Minute,
+		},
+		{
+			name:           "node not ready after grace period",
+			pod:            buildPod("not-ready-node", corev1.PodRunning, now.Add(-time.Hour)),
+			gracePeriod:    30,
+			wantFailed:     true,
+			wantNodeFailed: true,
+		},
+		{
+			name:           "node deleted",
+			pod:            buildPod("deleted-node", corev1.PodRunning, now.Add(-time.Hour)),
+			gracePeriod:    120,
+			wantFailed:     true,
+			wantNodeFailed: true,
+		},
+		{
+			name:          "pending within grace period",
+			pod:           buildPod("", corev1.PodPending, now.Add(-5*time.Minute)),
+			gracePeriod:   120,
+			wantRemaining: 5 * time.Minute,
+		},
+		{
+			name:        "pending after grace period",
+			pod:         buildPod("", corev1.PodPending, now.Add(-time.Hour)),
+			gracePeriod: 120,
+			wantFailed:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &PodReconciler{
+				client: fclient,
+				scheme: schema,
+			}
+			role.FailureDetection.NodeNotReadyGracePeriodSeconds = ptr.To(tt.gracePeriod)
+			trigger, remaining, err := r.checkPodFailure(context.TODO(), &role, tt.pod, now)
+			if err != nil {
+				t.Fatalf("checkPodFailure() error = %v", err)
+			}
+			if (trigger != nil) != tt.wantFailed {
+				t.Fatalf("checkPodFailure() trigger = %v, wantFailed %v", trigger, tt.wantFailed)
+			}
+			if trigger != nil && trigger.nodeFailed != tt.wantNodeFailed {
+				t.Errorf("checkPodFailure() nodeFailed = %v, want %v", trigger.nodeFailed, tt.wantNodeFailed)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("checkPodFailure() remaining = %v, want %v", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestPodReconciler_nodeToRBG(t *testing.T) {
+	schema := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(schema)
+	_ = workloadsv1alpha1.AddToScheme(schema)
+
+	buildPod := func(name, rbgName, nodeName string) *corev1.Pod {
+		pod := wrappers.BuildBasicPod().WithName(name).WithLabels(map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: rbgName,
+			workloadsv1alpha1.SetRoleLabelKey: "worker",
+		}).Obj()
+		pod.Namespace = "default"
+		pod.Spec.NodeName = nodeName
+		return &pod
+	}
+	fclient := fake.NewClientBuilder().WithScheme(schema).
+		WithIndex(&corev1.Pod{}, podNodeNameIndexKey, indexPodNodeName).
+		WithObjects(
+			buildPod("rbg-a-worker-0", "rbg-a", "node-1"),
+			buildPod("rbg-a-worker-1", "rbg-a", "node-1"),
+			buildPod("rbg-b-worker-0", "rbg-b", "node-2"),
+		).Build()
+
+	r := &PodReconciler{
+		client: fclient,
+		scheme: schema,
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	want := []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      "rbg-a",
+				Namespace: "default",
+			},
+		},
+	}
+	if got := r.nodeToRBG(context.TODO(), node); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeToRBG() = %v, want %v", got, want)
+	}
+}
+
+func TestPodReconciler_restartTriggers(t *testing.T) {
+	key := types.NamespacedName{Name: "test-rbg", Namespace: "default"}
+	r := &PodReconciler{}
+
+	// a burst of failures of a multi-node group
+	r.addRestartTrigger(key, restartTrigger{role: "worker", pod: "worker-0", reason: "PodDeleted: pod worker-0 was deleted"})
+	r.addRestartTrigger(key, restartTrigger{role: "worker", pod: "worker-1", reason: "PodDeleted: pod worker-1 was deleted"})
+	r.addRestartTrigger(key, restartTrigger{role: "worker", pod: "worker-0", reason: "PodDeleted: pod worker-0 was deleted"})
+	r.addRestartTrigger(key, restartTrigger{role: "router", pod: "router-0", reason: "PodDeleted: pod router-0 was deleted"})
+
+	triggers := r.getRestartTriggers(key)
+	if got, want := triggerPods(triggers), []string{"worker-0", "worker-1", "router-0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getRestartTriggers() pods = %v, want %v", got, want)
+	}
+	wantReason := "2 pod failures: PodDeleted: pod worker-0 was deleted; PodDeleted: pod worker-1 was deleted"
+	if got := coalescedRestartReason(triggers[:2]); got != wantReason {
+		t.Errorf("coalescedRestartReason() = %v, want %v", got, wantReason)
+	}
+
+	if remaining := r.removeRestartTriggers(key, "worker"); remaining != 1 {
+		t.Errorf("removeRestartTriggers() remaining = %v, want 1", remaining)
+	}
+	if remaining := r.removeRestartTriggers(key); remaining != 0 {
+		t.Errorf("removeRestartTriggers() remaining = %v, want 0", remaining)
+	}
+}
+
+func TestPodReconciler_createdBeforeRecreation(t *testing.T) {
+	key := types.NamespacedName{Name: "test-rbg", Namespace: "default"}
+	now := time.Now()
+	r := &PodReconciler{}
+	r.markRolesRecreated(key, []string{"worker"}, now)
+	rbg := wrappers.BuildBasicRoleBasedGroup(key.Name, key.Namespace).Obj()
+
+	oldPod := wrappers.BuildBasicPod().Obj()
+	oldPod.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	newPod := wrappers.BuildBasicPod().Obj()
+	newPod.CreationTimestamp = metav1.NewTime(now.Add(time.Second))
+
+	if !r.createdBeforeRecreation(rbg, "worker", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of old pod = false, want true")
+	}
+	if r.createdBeforeRecreation(rbg, "worker", &newPod) {
+		t.Errorf("createdBeforeRecreation() of new pod = true, want false")
+	}
+	if r.createdBeforeRecreation(rbg, "router", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of role not recreated = true, want false")
+	}
+}
+
+func TestPodReconciler_createdBeforeRecreation_AfterControllerRestart(t *testing.T) {
+	now := time.Now()
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("worker").WithRestartPolicy(workloadsv1alpha1.RecreateDependentRolesOnPodRestart).Obj(),
+		wrappers.BuildBasicRole("leader").WithRestartPolicy(workloadsv1alpha1.RecreateRBGOnPodRestart).Obj(),
+		wrappers.BuildBasicRole("router").WithRestartPolicy(workloadsv1alpha1.RecreateRoleInstanceOnPodRestart).Obj(),
+		wrappers.BuildBasicRole("cache").WithRestartPolicy(workloadsv1alpha1.RecreateDependentRolesOnPodRestart).Obj(),
+	}).Obj()
+	rbg.Status.RoleStatuses = []workloadsv1alpha1.RoleStatus{
+		{Name: "worker", RestartCount: 1, LastRestartTime: ptr.To(metav1.NewTime(now))},
+		{Name: "router", RestartCount: 1, LastRestartTime: ptr.To(metav1.NewTime(now))},
+	}
+	// the recreation times are lost with the restart of the controller
+	r := &PodReconciler{}
+
+	oldPod := wrappers.BuildBasicPod().Obj()
+	oldPod.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	newPod := wrappers.BuildBasicPod().Obj()
+	newPod.CreationTimestamp = metav1.NewTime(now.Add(time.Second))
+
+	if !r.createdBeforeRecreation(rbg, "worker", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of old pod of restarted role = false, want true")
+	}
+	if r.createdBeforeRecreation(rbg, "worker", &newPod) {
+		t.Errorf("createdBeforeRecreation() of new pod = true, want false")
+	}
+	// only the instance of the router pod was recreated
+	if r.createdBeforeRecreation(rbg, "router", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of role instance restart = true, want false")
+	}
+	if r.createdBeforeRecreation(rbg, "cache", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of role not restarted = true, want false")
+	}
+
+	// the restart of leader recreated all the roles
+	rbg.Status.RoleStatuses = append(rbg.Status.RoleStatuses,
+		workloadsv1alpha1.RoleStatus{Name: "leader", RestartCount: 1, LastRestartTime: ptr.To(metav1.NewTime(now))})
+	if !r.createdBeforeRecreation(rbg, "cache", &oldPod) {
+		t.Errorf("createdBeforeRecreation() of old pod after rbg restart = false, want true")
+	}
+}
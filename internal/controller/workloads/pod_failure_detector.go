@@ -0,0 +1,212 @@
+package workloads
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// podNodeNameIndexKey indexes pods by the node they are scheduled to
+const podNodeNameIndexKey = "spec.nodeName"
+
+func indexPodNodeName(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// detectPodFailures finds the pods of rbg which run on failed nodes or stay pending longer than the grace period
+// of their role, and records them as restart triggers. It returns the time until the earliest grace period of the
+// suspected pods expires, 0 if there is no suspected pod.
+func (r *PodReconciler) detectPodFailures(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+	key := types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}
+	now := time.Now()
+
+	var requeueAfter time.Duration
+	waitFor := func(d time.Duration) {
+		if requeueAfter == 0 || d < requeueAfter {
+			requeueAfter = d
+		}
+	}
+
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		if role.FailureDetection == nil || !restartHandledByRBG(role) {
+			continue
+		}
+
+		podList := &corev1.PodList{}
+		if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+			client.MatchingLabels(rbg.GetCommonLabelsFromRole(role))); err != nil {
+			return 0, err
+		}
+
+		for j := range podList.Items {
+			pod := &podList.Items[j]
+			if utils.PodDeleted(pod) {
+				continue
+			}
+			if _, ok := pod.Annotations[workloadsv1alpha1.RecreatingInstanceAnnotationKey]; ok {
+				continue
+			}
+
+			trigger, remaining, err := r.checkPodFailure(ctx, role, pod, now)
+			if err != nil {
+				return 0, err
+			}
+			if trigger != nil {
+				logger.Info("Detected pod failure", "role", role.Name, "pod", pod.Name, "reason", trigger.reason)
+				r.addRestartTrigger(key, *trigger)
+			} else if remaining > 0 {
+				waitFor(remaining)
+			}
+		}
+	}
+	return requeueAfter, nil
+}
+
+// checkPodFailure checks if the pod is failed according to the failure detection policy of its role.
+// If the pod is not failed yet but may fail after the grace period, the remaining grace period is returned.
+func (r *PodReconciler) checkPodFailure(
+	ctx context.Context, role *workloadsv1alpha1.RoleSpec, pod *corev1.Pod, now time.Time,
+) (*restartTrigger, time.Duration, error) {
+	policy := role.FailureDetection
+	var remaining time.Duration
+
+	if policy.NodeNotReadyGracePeriodSeconds != nil && pod.Spec.NodeName != "" {
+		node := &corev1.Node{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, 0, err
+		}
+		if apierrors.IsNotFound(err) {
+			return &restartTrigger{
+				role:       role.Name,
+				pod:        pod.Name,
+				reason:     fmt.Sprintf("NodeNotFound: node %s of pod %s was deleted", pod.Spec.NodeName, pod.Name),
+				nodeFailed: true,
+			}, 0, nil
+		}
+		if since, notReady := utils.NodeNotReadySince(node); notReady {
+			grace := time.Duration(*policy.NodeNotReadyGracePeriodSeconds) * time.Second
+			if now.Sub(since) >= grace {
+				return &restartTrigger{
+					role: role.Name,
+					pod:  pod.Name,
+					reason: fmt.Sprintf("NodeNotReady: node %s of pod %s is not ready for more than %ds",
+						node.Name, pod.Name, *policy.NodeNotReadyGracePeriodSeconds),
+					nodeFailed: true,
+				}, 0, nil
+			}
+			remaining = since.Add(grace).Sub(now)
+		}
+	}
+
+	if policy.PendingGracePeriodSeconds != nil && pod.Status.Phase == corev1.PodPending {
+		grace := time.Duration(*policy.PendingGracePeriodSeconds) * time.Second
+		created := pod.CreationTimestamp.Time
+		if now.Sub(created) >= grace {
+			return &restartTrigger{
+				role: role.Name,
+				pod:  pod.Name,
+				reason: fmt.Sprintf("PodPending: pod %s is pending for more than %ds",
+					pod.Name, *policy.PendingGracePeriodSeconds),
+			}, 0, nil
+		}
+		if d := created.Add(grace).Sub(now); remaining == 0 || d < remaining {
+			remaining = d
+		}
+	}
+
+	return nil, remaining, nil
+}
+
+// forceDeleteNodeFailedPods force deletes the failed pods which run on failed nodes. The kubelet of a failed
+// node can not confirm the deletion, so the pods would be terminating forever.
+func (r *PodReconciler) forceDeleteNodeFailedPods(
+	ctx context.Context, namespace string, triggers []restartTrigger,
+) error {
+	logger := log.FromContext(ctx)
+	for _, t := range triggers {
+		if !t.nodeFailed {
+			continue
+		}
+		pod := &corev1.Pod{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: t.pod, Namespace: namespace}, pod); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		logger.Info("Force delete pod on failed node", "pod", t.pod)
+		if err := r.markAndDeletePod(ctx, pod, t.pod, client.GracePeriodSeconds(0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeToRBG enqueues the rbgs which have pods on the node.
+func (r *PodReconciler) nodeToRBG(ctx context.Context, obj client.Object) []reconcile.Request {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.MatchingFields{podNodeNameIndexKey: node.Name},
+		client.HasLabels{workloadsv1alpha1.SetNameLabelKey}); err != nil {
+		log.FromContext(ctx).Error(err, "list pods on node error", "node", node.Name)
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	seen := make(map[types.NamespacedName]bool)
+	for _, pod := range podList.Items {
+		key := types.NamespacedName{Name: pod.Labels[workloadsv1alpha1.SetNameLabelKey], Namespace: pod.Namespace}
+		if !seen[key] {
+			seen[key] = true
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// nodePredicate only passes the events of nodes which become NotReady or are deleted.
+func nodePredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok1 := e.ObjectOld.(*corev1.Node)
+			newNode, ok2 := e.ObjectNew.(*corev1.Node)
+			if ok1 && ok2 {
+				return utils.NodeReady(oldNode) && !utils.NodeReady(newNode)
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
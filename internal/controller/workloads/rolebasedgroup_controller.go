@@ -20,10 +20,15 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,11 +37,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -65,6 +73,9 @@ type RoleBasedGroupReconciler struct {
 	apiReader client.Reader
 	scheme    *runtime.Scheme
 	recorder  record.EventRecorder
+	cache     cache.Cache
+	// controller is the built controller, which watches the optional CRDs once they are installed
+	controller controller.Controller
 }
 
 func NewRoleBasedGroupReconciler(mgr ctrl.Manager) *RoleBasedGroupReconciler {
@@ -73,6 +84,7 @@ func NewRoleBasedGroupReconciler(mgr ctrl.Manager) *RoleBasedGroupReconciler {
 		apiReader: mgr.GetAPIReader(),
 		scheme:    mgr.GetScheme(),
 		recorder:  mgr.GetEventRecorderFor("RoleBasedGroup"),
+		cache:     mgr.GetCache(),
 	}
 }
 
@@ -97,7 +109,7 @@ func (r *RoleBasedGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Process roles in dependency order
 	dependencyManager := dependency.NewDefaultDependencyManager(r.scheme, r.client)
-	sortedRoles, err := dependencyManager.SortRoles(ctx, rbg)
+	levels, err := dependencyManager.SortRolesByLevel(ctx, rbg)
 	if err != nil {
 		r.recorder.Event(rbg, corev1.EventTypeWarning, InvalidRoleDependency, err.Error())
 		return ctrl.Result{}, err
@@ -125,61 +137,92 @@ func (r *RoleBasedGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
-	// Reconcile role, add & update
+	// Process PodDisruptionBudgets
+	if err := reconciler.NewPodDisruptionBudgetReconciler(r.client).Reconcile(ctx, rbg); err != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedReconcilePDB, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// Process the Services of roles
+	if err := reconciler.NewServiceReconciler(r.client).Reconcile(ctx, rbg); err != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedReconcileService, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile roles level by level in dependency order. The roles of a level are reconciled in parallel, and the
+	// roles whose dependencies are not met are skipped until an event of their dependencies enqueues the rbg again.
 	roleStatuses := []workloadsv1alpha1.RoleStatus{}
 	var updateStatus bool
-	for _, role := range sortedRoles {
-		logger := log.FromContext(ctx)
-		roleCtx := log.IntoContext(ctx, logger.WithValues("role", role.Name))
-
-		// first check whether watch lws cr
-		dynamicWatchCustomCRD(roleCtx, role.Workload.Kind)
-		// Check dependencies first
-		ready, err := dependencyManager.CheckDependencyReady(roleCtx, rbg, role)
-		if err != nil {
-			r.recorder.Event(rbg, corev1.EventTypeWarning, FailedCheckRoleDependency, err.Error())
-			return ctrl.Result{}, err
-		}
-		if !ready {
-			logger.Info("Dependencies not met, requeuing", "role", role.Name)
-			return ctrl.Result{RequeueAfter: 5}, nil
+	var blockedRoles []string
+	var errs []error
+	for _, roles := range levels {
+		for _, role := range roles {
+			// first check whether watch lws cr
+			dynamicWatchCustomCRD(ctx, role.Workload.Kind)
 		}
 
-		reconciler, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
-		if err != nil {
-			logger.Error(err, "Failed to create workload reconciler")
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-				"Failed to reconcile role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
+		results := make([]roleResult, len(roles))
+		var wg sync.WaitGroup
+		for i, role := range roles {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				roleCtx := log.IntoContext(ctx, logger.WithValues("role", role.Name))
+				results[i] = r.reconcileRole(roleCtx, rbg, role, dependencyManager)
+			}()
 		}
+		wg.Wait()
 
-		if err := reconciler.Reconciler(roleCtx, rbg, role); err != nil {
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-				"Failed to reconcile role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
-		}
-
-		if err := r.ReconcileScalingAdapter(roleCtx, rbg, role); err != nil {
-			logger.Error(err, "Failed to reconcile scaling adapter")
-			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedCreateScalingAdapter,
-				"Failed to reconcile scaling adapter for role %s: %v", role.Name, err)
-			return ctrl.Result{}, err
+		for i, result := range results {
+			if result.err != nil {
+				errs = append(errs, result.err)
+				continue
+			}
+			if result.blocked {
+				blockedRoles = append(blockedRoles, roles[i].Name)
+			}
+			updateStatus = updateStatus || result.updateStatus
+			roleStatuses = append(roleStatuses, result.status)
 		}
-
-		roleStatus, updateRoleStatus, err := reconciler.ConstructRoleStatus(roleCtx, rbg, role)
-		if err != nil {
-			if !apierrors.IsNotFound(err) {
-				r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
-					"Failed to construct role %s status: %v", role.Name, err)
+	}
+	if len(errs) > 0 {
+		// record the status of the other roles, the startup barriers of the pods wait for it. The rbg is not ready
+		// as the failed roles have no status.
+		if updateStatus {
+			if err := r.updateRBGStatus(ctx, rbg, roleStatuses, blockedRoles); err != nil {
+				r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedUpdateStatus,
+					"Failed to update status for %s: %v", rbg.Name, err)
 			}
-			return ctrl.Result{}, err
 		}
-		updateStatus = updateStatus || updateRoleStatus
-		roleStatuses = append(roleStatuses, roleStatus)
+		return ctrl.Result{}, errors.NewAggregate(errs)
+	}
+
+	// Expose the entry role through Gateway API once all roles are ready
+	r.watchExposeCRDs(ctx, rbg)
+	exposedCondition, err := reconciler.NewExposeReconciler(r.client).Reconcile(ctx, rbg, rolesReady(roleStatuses))
+	if err != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedReconcileExpose, err.Error())
+		return ctrl.Result{}, err
+	}
+	updateStatus = setExposedCondition(rbg, exposedCondition) || updateStatus
+
+	// Register the ready backends into the roles with registration, e.g. a router. The registrations are recorded
+	// in the status even if some requests failed, and the failed ones are retried by requeuing the rbg after a delay,
+	// as the requests may fail for as long as the instances are unresponsive.
+	registrations, registrationErr := reconciler.NewRegistrationReconciler(r.client).Reconcile(ctx, rbg)
+	if registrationErr != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedReconcileRegistration, registrationErr.Error())
+	}
+	if !apiequality.Semantic.DeepEqual(rbg.Status.Registrations, registrations) {
+		rbg.Status.Registrations = registrations
+		updateStatus = true
 	}
 
+	// the status records the generation it is computed for, so that the owner of the rbg knows
+	// when the rbg has observed its latest spec.
+	updateStatus = updateStatus || rbg.Status.ObservedGeneration != rbg.Generation
 	if updateStatus {
-		if err := r.updateRBGStatus(ctx, rbg, roleStatuses); err != nil {
+		if err := r.updateRBGStatus(ctx, rbg, roleStatuses, blockedRoles); err != nil {
 			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedUpdateStatus,
 				"Failed to update status for %s: %v", rbg.Name, err)
 			return ctrl.Result{}, err
@@ -193,10 +236,98 @@ func (r *RoleBasedGroupReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	if registrationErr != nil {
+		return ctrl.Result{RequeueAfter: reconciler.RegistrationRetryInterval}, nil
+	}
+
 	r.recorder.Event(rbg, corev1.EventTypeNormal, Succeed, "ReconcileSucceed")
 	return ctrl.Result{}, nil
 }
 
+// roleResult is the result of reconciling a role.
+type roleResult struct {
+	status       workloadsv1alpha1.RoleStatus
+	updateStatus bool
+	// blocked is set if the dependencies of the role are not met, so that its workload is not reconciled
+	blocked bool
+	err     error
+}
+
+// reconcileRole reconciles the workload of role if its dependencies are met, and returns the status of the role.
+// The events of the failures are recorded here, as the roles of a level are reconciled in parallel.
+func (r *RoleBasedGroupReconciler) reconcileRole(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+	dependencyManager dependency.DependencyManager,
+) roleResult {
+	logger := log.FromContext(ctx)
+
+	workloadReconciler, err := reconciler.NewWorkloadReconciler(role.Workload, r.scheme, r.client)
+	if err != nil {
+		logger.Error(err, "Failed to create workload reconciler")
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to reconcile role %s: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+
+	// Check dependencies first
+	ready, err := dependencyManager.CheckDependencyReady(ctx, rbg, role)
+	if err != nil {
+		r.recorder.Event(rbg, corev1.EventTypeWarning, FailedCheckRoleDependency, err.Error())
+		return roleResult{err: err}
+	}
+	if !ready {
+		logger.Info("Dependencies not met, waiting")
+		status, updateStatus, err := r.blockedRoleStatus(ctx, rbg, role, workloadReconciler)
+		return roleResult{status: status, updateStatus: updateStatus, blocked: true, err: err}
+	}
+
+	if err := workloadReconciler.Reconciler(ctx, rbg, role); err != nil {
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+			"Failed to reconcile role %s: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+
+	if err := r.ReconcileScalingAdapter(ctx, rbg, role); err != nil {
+		logger.Error(err, "Failed to reconcile scaling adapter")
+		r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedCreateScalingAdapter,
+			"Failed to reconcile scaling adapter for role %s: %v", role.Name, err)
+		return roleResult{err: err}
+	}
+
+	status, updateStatus, err := workloadReconciler.ConstructRoleStatus(ctx, rbg, role)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.recorder.Eventf(rbg, corev1.EventTypeWarning, FailedReconcileWorkload,
+				"Failed to construct role %s status: %v", role.Name, err)
+		}
+		return roleResult{err: err}
+	}
+	return roleResult{status: status, updateStatus: updateStatus}
+}
+
+// blockedRoleStatus returns the status of a role whose dependencies are not met. The workload of the role may
+// exist from an earlier reconciliation, otherwise none of the desired replicas is ready.
+func (r *RoleBasedGroupReconciler) blockedRoleStatus(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+	workloadReconciler reconciler.WorkloadReconciler,
+) (workloadsv1alpha1.RoleStatus, bool, error) {
+	status, updateStatus, err := workloadReconciler.ConstructRoleStatus(ctx, rbg, role)
+	if !apierrors.IsNotFound(err) {
+		return status, updateStatus, err
+	}
+
+	status, found := rbg.GetRoleStatus(role.Name)
+	replicas := ptr.Deref(role.Replicas, 1)
+	if found && status.Replicas == replicas && status.ReadyReplicas == 0 && status.UpdatedReplicas == 0 {
+		return status, false, nil
+	}
+	status.Name = role.Name
+	status.Replicas = replicas
+	status.ReadyReplicas = 0
+	status.UpdatedReplicas = 0
+	return status, true, nil
+}
+
 func (r *RoleBasedGroupReconciler) deleteRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
 	errs := make([]error, 0)
 	deployRecon := reconciler.NewDeploymentReconciler(r.scheme, r.client)
@@ -221,18 +352,21 @@ func (r *RoleBasedGroupReconciler) deleteRoles(ctx context.Context, rbg *workloa
 	return errors.NewAggregate(errs)
 }
 
-func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus []workloadsv1alpha1.RoleStatus) error {
+func (r *RoleBasedGroupReconciler) updateRBGStatus(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleStatus []workloadsv1alpha1.RoleStatus,
+	blockedRoles []string,
+) error {
 	// update ready condition
-	rbgReady := true
-	for _, role := range roleStatus {
-		if role.ReadyReplicas != role.Replicas {
-			rbgReady = false
-			break
-		}
-	}
-
 	var readyCondition metav1.Condition
-	if rbgReady {
+	if len(blockedRoles) > 0 {
+		readyCondition = metav1.Condition{
+			Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "DependenciesNotMet",
+			Message:            fmt.Sprintf("Roles waiting for their dependencies: %s", strings.Join(blockedRoles, ", ")),
+		}
+	} else if len(roleStatus) == len(rbg.Spec.Roles) && rolesReady(roleStatus) {
 		readyCondition = metav1.Condition{
 			Type:               string(workloadsv1alpha1.RoleBasedGroupReady),
 			Status:             metav1.ConditionTrue,
@@ -251,6 +385,8 @@ func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *wor
 	}
 
 	setCondition(rbg, readyCondition)
+	// a failed rbg is not failed anymore once its spec is updated
+	resetFailedCondition(rbg, time.Now())
 
 	// update role status
 	for i := range roleStatus {
@@ -259,7 +395,8 @@ func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *wor
 			// if found, update
 			if roleStatus[i].Name == oldStatus.Name {
 				found = true
-				if roleStatus[i].Replicas != oldStatus.Replicas || roleStatus[i].ReadyReplicas != oldStatus.ReadyReplicas {
+				if roleStatus[i].Replicas != oldStatus.Replicas || roleStatus[i].ReadyReplicas != oldStatus.ReadyReplicas ||
+					roleStatus[i].UpdatedReplicas != oldStatus.UpdatedReplicas {
 					rbg.Status.RoleStatuses[j] = roleStatus[i]
 				}
 				break
@@ -271,13 +408,27 @@ func (r *RoleBasedGroupReconciler) updateRBGStatus(ctx context.Context, rbg *wor
 	}
 
 	// update rbg status
+	rbg.Status.ObservedGeneration = rbg.Generation
 	rbgApplyConfig := utils.RoleBasedGroup(rbg.Name, rbg.Namespace, rbg.Kind, rbg.APIVersion).
-		WithStatus(utils.RbgStatus().WithRoleStatuses(rbg.Status.RoleStatuses).WithConditions(rbg.Status.Conditions))
+		WithStatus(utils.RbgStatus().
+			WithObservedGeneration(rbg.Status.ObservedGeneration).
+			WithRoleStatuses(rbg.Status.RoleStatuses).
+			WithRegistrations(rbg.Status.Registrations).
+			WithConditions(rbg.Status.Conditions))
 
 	return utils.PatchObjectApplyConfiguration(ctx, r.client, rbgApplyConfig, utils.PatchStatus)
 
 }
 
+func rolesReady(roleStatus []workloadsv1alpha1.RoleStatus) bool {
+	for _, role := range roleStatus {
+		if role.ReadyReplicas != role.Replicas {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *RoleBasedGroupReconciler) ReconcileScalingAdapter(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleSpec *workloadsv1alpha1.RoleSpec) error {
 	logger := log.FromContext(ctx)
 	roleName := roleSpec.Name
@@ -371,12 +522,34 @@ func (r *RoleBasedGroupReconciler) CleanupOrphanedScalingAdapters(ctx context.Co
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *RoleBasedGroupReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(), &workloadsv1alpha1.RoleBasedGroup{}, rbgObjectRefIndexKey, indexRBGObjectRefs,
+	); err != nil {
+		return err
+	}
+
 	runtimeController = ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		For(&workloadsv1alpha1.RoleBasedGroup{}, builder.WithPredicates(RBGPredicate())).
 		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(WorkloadPredicate())).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(WorkloadPredicate())).
 		Owns(&corev1.Service{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToDiscoveryRBG),
+			builder.WithPredicates(DiscoveryPodPredicate())).
+		// the objects roles depend on or render their discovery config from, only the metadata of the Secrets
+		// is cached
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.objectToRBGs(workloadsv1alpha1.SecretDependencyObject)),
+			builder.OnlyMetadata).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.objectToRBGs(workloadsv1alpha1.ConfigMapDependencyObject))).
+		Watches(&corev1.PersistentVolumeClaim{},
+			handler.EnqueueRequestsFromMapFunc(r.objectToRBGs(workloadsv1alpha1.PersistentVolumeClaimDependencyObject))).
+		Watches(&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.objectToRBGs(workloadsv1alpha1.JobDependencyObject))).
+		Watches(&workloadsv1alpha1.RoleBasedGroup{},
+			handler.EnqueueRequestsFromMapFunc(r.objectToRBGs(workloadsv1alpha1.RoleBasedGroupDependencyObject))).
 		Named("workloads-rolebasedgroup")
 
 	err := utils.CheckCrdExists(r.apiReader, utils.LwsCrdName)
@@ -390,7 +563,8 @@ func (r *RoleBasedGroupReconciler) SetupWithManager(mgr ctrl.Manager, options co
 		runtimeController.Owns(&schev1alpha1.PodGroup{})
 	}
 
-	return runtimeController.Complete(r)
+	r.controller, err = runtimeController.Build(r)
+	return err
 }
 
 // CheckCrdExists checks if the specified Custom Resource Definition (CRD) exists in the Kubernetes cluster.
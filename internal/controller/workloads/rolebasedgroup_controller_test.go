@@ -17,20 +17,30 @@ limitations under the License.
 package workloads
 
 import (
+	"context"
 	"errors"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/dependency"
 	"sigs.k8s.io/rbgs/pkg/utils"
+	"sigs.k8s.io/rbgs/test/wrappers"
 )
 
 func TestRoleBasedGroupReconciler_CheckCrdExists(t *testing.T) {
@@ -350,3 +360,91 @@ func Test_hasValidOwnerRef(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleBasedGroupReconciler_reconcileRole_DependenciesNotMet(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = workloadsv1alpha1.AddToScheme(testScheme)
+
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(
+		[]workloadsv1alpha1.RoleSpec{
+			wrappers.BuildBasicRole("decode").WithReplicas(2).WithDependsOn([]workloadsv1alpha1.Dependency{{
+				Object: &workloadsv1alpha1.DependencyObject{
+					Kind: workloadsv1alpha1.SecretDependencyObject, Name: "token",
+				},
+			}}).Obj(),
+		},
+	).Obj()
+	c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(rbg).Build()
+	r := &RoleBasedGroupReconciler{client: c, scheme: testScheme, recorder: record.NewFakeRecorder(10)}
+	dependencyManager := dependency.NewDefaultDependencyManager(testScheme, c)
+	ctx := context.TODO()
+
+	// the role is skipped, and reported as not ready
+	result := r.reconcileRole(ctx, rbg, &rbg.Spec.Roles[0], dependencyManager)
+	if result.err != nil || !result.blocked || !result.updateStatus {
+		t.Fatalf("reconcileRole() = %+v, want blocked", result)
+	}
+	wantStatus := workloadsv1alpha1.RoleStatus{Name: "decode", Replicas: 2}
+	if result.status != wantStatus {
+		t.Errorf("reconcileRole() status = %+v, want %+v", result.status, wantStatus)
+	}
+	sts := &appsv1.StatefulSet{}
+	err := c.Get(ctx, types.NamespacedName{Name: "test-rbg-decode", Namespace: "default"}, sts)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("workload of the blocked role is created, err = %v", err)
+	}
+
+	// the status is not updated again while the role waits
+	rbg.Status.RoleStatuses = []workloadsv1alpha1.RoleStatus{result.status}
+	result = r.reconcileRole(ctx, rbg, &rbg.Spec.Roles[0], dependencyManager)
+	if result.err != nil || !result.blocked || result.updateStatus {
+		t.Errorf("reconcileRole() = %+v, want blocked without status update", result)
+	}
+
+	// the events of the Secret enqueue the rbg
+	if refs := indexRBGObjectRefs(rbg); !reflect.DeepEqual(refs, []string{"Secret/token"}) {
+		t.Errorf("indexRBGObjectRefs() = %v, want [Secret/token]", refs)
+	}
+}
+
+func TestRoleBasedGroupReconciler_objectToRBGs(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(testScheme)
+	_ = workloadsv1alpha1.AddToScheme(testScheme)
+
+	dependsOn := func(kind workloadsv1alpha1.DependencyObjectKind, name string) []workloadsv1alpha1.Dependency {
+		return []workloadsv1alpha1.Dependency{{Object: &workloadsv1alpha1.DependencyObject{Kind: kind, Name: name}}}
+	}
+	templateRole := wrappers.BuildBasicRole("router").Obj()
+	templateRole.Discovery = &workloadsv1alpha1.RoleDiscoveryConfig{
+		Format:   workloadsv1alpha1.TemplateDiscoveryFormat,
+		Template: &workloadsv1alpha1.DiscoveryTemplate{ConfigMapName: "config"},
+	}
+	dependent := wrappers.BuildBasicRoleBasedGroup("dependent", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("decode").WithDependsOn(
+			dependsOn(workloadsv1alpha1.ConfigMapDependencyObject, "config")).Obj(),
+	}).Obj()
+	rendering := wrappers.BuildBasicRoleBasedGroup("rendering", "default").
+		WithRoles([]workloadsv1alpha1.RoleSpec{templateRole}).Obj()
+	other := wrappers.BuildBasicRoleBasedGroup("other", "default").WithRoles([]workloadsv1alpha1.RoleSpec{
+		wrappers.BuildBasicRole("decode").WithDependsOn(dependsOn(workloadsv1alpha1.JobDependencyObject, "config")).Obj(),
+	}).Obj()
+	otherNamespace := wrappers.BuildBasicRoleBasedGroup("dependent", "other").WithRoles(dependent.Spec.Roles).Obj()
+
+	c := fake.NewClientBuilder().WithScheme(testScheme).
+		WithIndex(&workloadsv1alpha1.RoleBasedGroup{}, rbgObjectRefIndexKey, indexRBGObjectRefs).
+		WithObjects(dependent, rendering, other, otherNamespace).Build()
+	r := &RoleBasedGroupReconciler{client: c, scheme: testScheme}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+	got := r.objectToRBGs(workloadsv1alpha1.ConfigMapDependencyObject)(context.TODO(), configMap)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "dependent", Namespace: "default"}},
+		{NamespacedName: types.NamespacedName{Name: "rendering", Namespace: "default"}},
+	}
+	slices.SortFunc(got, func(a, b reconcile.Request) int { return strings.Compare(a.Name, b.Name) })
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("objectToRBGs() = %v, want %v", got, want)
+	}
+}
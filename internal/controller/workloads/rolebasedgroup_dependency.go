@@ -0,0 +1,67 @@
+package workloads
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// rbgObjectRefIndexKey indexes rbgs by the objects in their namespace their roles depend on or render their
+// discovery config from, as <kind>/<name>
+const rbgObjectRefIndexKey = "spec.roles.objectRefs"
+
+func indexRBGObjectRefs(obj client.Object) []string {
+	rbg, ok := obj.(*workloadsv1alpha1.RoleBasedGroup)
+	if !ok {
+		return nil
+	}
+	var refs []string
+	for _, role := range rbg.Spec.Roles {
+		for _, dep := range role.DependsOn {
+			if dep.Object != nil {
+				refs = append(refs, objectRef(dep.Object.Kind, dep.Object.Name))
+			}
+		}
+		if role.GetDiscoveryFormat() == workloadsv1alpha1.TemplateDiscoveryFormat && role.Discovery.Template != nil {
+			refs = append(refs, objectRef(workloadsv1alpha1.ConfigMapDependencyObject, role.Discovery.Template.ConfigMapName))
+		}
+	}
+	slices.Sort(refs)
+	return slices.Compact(refs)
+}
+
+// objectRef returns the value of rbgObjectRefIndexKey for the object of kind and name.
+func objectRef(kind workloadsv1alpha1.DependencyObjectKind, name string) string {
+	return string(kind) + "/" + name
+}
+
+// objectToRBGs returns a map func enqueuing the rbgs in the namespace of an object of kind which have a role
+// depending on the object or rendering its discovery config from it, so that the dependency is checked again or
+// the config is re-rendered when the object changes instead of polling it. The dependencies on roles are
+// triggered by the events of the workloads owned by the rbg.
+func (r *RoleBasedGroupReconciler) objectToRBGs(kind workloadsv1alpha1.DependencyObjectKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		rbgList := &workloadsv1alpha1.RoleBasedGroupList{}
+		if err := r.client.List(ctx, rbgList, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{rbgObjectRefIndexKey: objectRef(kind, obj.GetName())}); err != nil {
+			log.FromContext(ctx).Error(err, "list rbgs referencing object error", "kind", kind, "name", obj.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, rbg := range rbgList.Items {
+			if rbg.DeletionTimestamp != nil {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,58 @@
+package workloads
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// DiscoveryPodPredicate filters the pod events which change the instances listed in the discovery config of a rbg
+// discovering pods: the pod is created or deleted, or its IP or readiness is changed.
+func DiscoveryPodPredicate() predicate.Funcs {
+	isRBGPod := func(obj client.Object) bool {
+		_, ok := obj.GetLabels()[workloadsv1alpha1.SetNameLabelKey]
+		return ok
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isRBGPod(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok1 := e.ObjectOld.(*corev1.Pod)
+			newPod, ok2 := e.ObjectNew.(*corev1.Pod)
+			if !ok1 || !ok2 || !isRBGPod(newPod) {
+				return false
+			}
+			return oldPod.Status.PodIP != newPod.Status.PodIP ||
+				utils.PodRunningAndReady(*oldPod) != utils.PodRunningAndReady(*newPod) ||
+				(oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isRBGPod(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// podToDiscoveryRBG enqueues the rbg of a pod if the rbg discovers pods or registers backends, so that its discovery
+// config is rebuilt and its backends are registered.
+func (r *RoleBasedGroupReconciler) podToDiscoveryRBG(ctx context.Context, obj client.Object) []reconcile.Request {
+	rbgName := obj.GetLabels()[workloadsv1alpha1.SetNameLabelKey]
+	rbg := &workloadsv1alpha1.RoleBasedGroup{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbgName, Namespace: obj.GetNamespace()}, rbg); err != nil {
+		return nil
+	}
+	if !(rbg.DiscoverPods() || rbg.RegistersBackends()) || rbg.DeletionTimestamp != nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}}}
+}
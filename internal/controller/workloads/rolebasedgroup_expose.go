@@ -0,0 +1,60 @@
+package workloads
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// watchExposeCRDs watches the Gateway API objects owned by rbgs once a rbg is exposed and the CRDs are installed,
+// so that the Exposed condition follows the status of the HTTPRoute.
+func (r *RoleBasedGroupReconciler) watchExposeCRDs(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) {
+	if rbg.Spec.Expose == nil || r.controller == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+	for crdName, gvk := range map[string]schema.GroupVersionKind{
+		utils.HTTPRouteCrdName:     reconciler.HTTPRouteGVK,
+		utils.InferencePoolCrdName: reconciler.InferencePoolGVK,
+	} {
+		if _, watched := watchedWorkload.Load(crdName); watched {
+			continue
+		}
+		if err := utils.CheckCrdExists(r.apiReader, crdName); err != nil {
+			logger.V(1).Info("skip watching expose CRD", "crd", crdName, "reason", err.Error())
+			continue
+		}
+		if _, loaded := watchedWorkload.LoadOrStore(crdName, struct{}{}); !loaded {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(gvk)
+			// the builder is already built, so the watch is added to the running controller
+			src := source.Kind[client.Object](r.cache, obj, handler.EnqueueRequestForOwner(
+				r.scheme, r.client.RESTMapper(), &workloadsv1alpha1.RoleBasedGroup{}, handler.OnlyControllerOwner()))
+			if err := r.controller.Watch(src); err != nil {
+				watchedWorkload.Delete(crdName)
+				logger.Error(err, "failed to watch expose CRD", "crd", crdName)
+				continue
+			}
+			logger.Info("rbgs controller watch expose CRD", "crd", crdName)
+		}
+	}
+}
+
+// setExposedCondition sets the Exposed condition of rbg, or removes it if condition is nil. It returns whether the
+// status of rbg is changed.
+func setExposedCondition(rbg *workloadsv1alpha1.RoleBasedGroup, condition *metav1.Condition) bool {
+	if condition == nil {
+		return meta.RemoveStatusCondition(&rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupExposed))
+	}
+	return meta.SetStatusCondition(&rbg.Status.Conditions, *condition)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -34,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -83,6 +85,9 @@ func (r *RoleBasedGroupScalingAdapterReconciler) Reconcile(ctx context.Context,
 	}
 
 	logger.Info("Start reconciling")
+	if rbgScalingAdapter.Spec.ScaleTargetRef.Kind == workloadsv1alpha1.AdapterScaleTargetRBGSet {
+		return r.reconcileRBGSetTarget(ctx, rbgScalingAdapter)
+	}
 	rbgScalingAdapterName := rbgScalingAdapter.Name
 	rbgName := rbgScalingAdapter.Spec.ScaleTargetRef.Name
 	targetRoleName := rbgScalingAdapter.Spec.ScaleTargetRef.Role
@@ -211,6 +216,108 @@ func (r *RoleBasedGroupScalingAdapterReconciler) Reconcile(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// reconcileRBGSetTarget binds the scaling adapter to the replicas of a rbgset, and scales the rbgset to the
+// replicas of the adapter.
+func (r *RoleBasedGroupScalingAdapterReconciler) reconcileRBGSetTarget(
+	ctx context.Context, rbgScalingAdapter *workloadsv1alpha1.RoleBasedGroupScalingAdapter,
+) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	rbgSetName := rbgScalingAdapter.Spec.ScaleTargetRef.Name
+
+	rbgset := &workloadsv1alpha1.RoleBasedGroupSet{}
+	if err := r.client.Get(
+		ctx, types.NamespacedName{Name: rbgSetName, Namespace: rbgScalingAdapter.Namespace}, rbgset,
+	); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(
+			rbgScalingAdapter, corev1.EventTypeNormal, FailedGetRBGSet,
+			"Failed to get scale target rbgset %s: %v", rbgSetName, err,
+		)
+		if rbgScalingAdapter.Status.Phase != workloadsv1alpha1.AdapterPhaseNotBound {
+			rbgScalingAdapterApplyConfig := utils.RoleBasedGroupScalingAdapter(rbgScalingAdapter).
+				WithStatus(utils.RbgScalingAdapterStatus(rbgScalingAdapter.Status).WithPhase(workloadsv1alpha1.AdapterPhaseNotBound))
+			if err := utils.PatchObjectApplyConfiguration(
+				ctx, r.client, rbgScalingAdapterApplyConfig, utils.PatchStatus,
+			); err != nil {
+				logger.Error(err, "Failed to update status", "rbgScalingAdapterName", rbgScalingAdapter.Name)
+			}
+		}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if !scale.IsScalingAdapterManagedByRBGSet(rbgScalingAdapter, rbgset) {
+		logger.Info("Skip to reconcile the scaling adapter which is not managed by rbgset-controller")
+		return ctrl.Result{}, nil
+	}
+
+	// init adapter with phase bound, selector and the current replicas of the rbgset
+	if rbgScalingAdapter.Status.Phase != workloadsv1alpha1.AdapterPhaseBound {
+		rbgScalingAdapterSpecApplyConfig := utils.RoleBasedGroupScalingAdapter(rbgScalingAdapter).
+			WithSpec(utils.RbgScalingAdapterSpec(rbgScalingAdapter.Spec).WithReplicas(rbgset.Spec.Replicas))
+		if err := utils.PatchObjectApplyConfiguration(
+			ctx, r.client, rbgScalingAdapterSpecApplyConfig, utils.PatchSpec,
+		); err != nil {
+			logger.Error(err, "Failed to init spec.replicas", "rbgScalingAdapterName", rbgScalingAdapter.Name)
+			return ctrl.Result{}, err
+		}
+
+		rbgScalingAdapterStatusApplyConfig := utils.RoleBasedGroupScalingAdapter(rbgScalingAdapter).
+			WithStatus(
+				utils.RbgScalingAdapterStatus(rbgScalingAdapter.Status).
+					WithReplicas(rbgset.Spec.Replicas, false).
+					WithPhase(workloadsv1alpha1.AdapterPhaseBound).WithSelector(rbgSetPodSelector(rbgset)),
+			)
+		if err := utils.PatchObjectApplyConfiguration(
+			ctx, r.client, rbgScalingAdapterStatusApplyConfig, utils.PatchStatus,
+		); err != nil {
+			logger.Error(err, "Failed to update status", "rbgScalingAdapterName", rbgScalingAdapter.Name)
+			return ctrl.Result{}, err
+		}
+		r.recorder.Eventf(
+			rbgScalingAdapter, corev1.EventTypeNormal, SuccessfulBound,
+			"Succeed to find scale target rbgset [%s]", rbgSetName,
+		)
+		return ctrl.Result{}, nil
+	}
+
+	desiredReplicas, currentReplicas := rbgScalingAdapter.Spec.Replicas, rbgset.Spec.Replicas
+	if desiredReplicas == nil || currentReplicas == nil || *desiredReplicas == *currentReplicas {
+		// nothing to do
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Start scaling", "desired replicas", *desiredReplicas, "current replicas", *currentReplicas)
+	oldReplicas := *currentReplicas
+	patch := client.MergeFrom(rbgset.DeepCopy())
+	rbgset.Spec.Replicas = ptr.To(*desiredReplicas)
+	if err := r.client.Patch(ctx, rbgset, patch); err != nil {
+		r.recorder.Eventf(
+			rbgScalingAdapter, corev1.EventTypeNormal, FailedScale,
+			"Failed to scale target rbgset [%s] from %v to %v replicas: %v",
+			rbgSetName, oldReplicas, *desiredReplicas, err,
+		)
+		return ctrl.Result{}, err
+	}
+
+	rbgScalingAdapterApplyConfig := utils.RoleBasedGroupScalingAdapter(rbgScalingAdapter).
+		WithStatus(utils.RbgScalingAdapterStatus(rbgScalingAdapter.Status).WithReplicas(desiredReplicas, true))
+	if err := utils.PatchObjectApplyConfiguration(
+		ctx, r.client, rbgScalingAdapterApplyConfig, utils.PatchStatus,
+	); err != nil {
+		logger.Error(err, "Failed to update status", "rbgScalingAdapterName", rbgScalingAdapter.Name)
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Scale successfully", "old replicas", oldReplicas, "new replicas", *desiredReplicas)
+	r.recorder.Eventf(
+		rbgScalingAdapter, corev1.EventTypeNormal, SuccessfulScale,
+		"Succeed to scale target rbgset [%s] from %v to %v replicas", rbgSetName, oldReplicas, *desiredReplicas,
+	)
+	return ctrl.Result{}, nil
+}
+
 func (r *RoleBasedGroupScalingAdapterReconciler) UpdateAdapterOwnerReference(
 	ctx context.Context,
 	rbgScalingAdapter *workloadsv1alpha1.RoleBasedGroupScalingAdapter,
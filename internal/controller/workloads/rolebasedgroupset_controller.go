@@ -18,11 +18,18 @@ package workloads
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,14 +37,23 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/scale"
+	"sigs.k8s.io/rbgs/pkg/scheduler"
 	"sigs.k8s.io/rbgs/pkg/utils"
 )
 
@@ -80,6 +96,13 @@ func (r *RoleBasedGroupSetReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.reconcileScalingAdapter(ctx, rbgset); err != nil {
+		logger.Error(err, "Failed to reconcile scaling adapter")
+		r.recorder.Eventf(rbgset, corev1.EventTypeWarning, FailedCreateScalingAdapter,
+			"Failed to reconcile scaling adapter: %v", err)
+		return ctrl.Result{}, err
+	}
+
 	// 2. List all child RoleBasedGroup instances currently associated with this RoleBasedGroupSet.
 	var rbglist workloadsv1alpha1.RoleBasedGroupList
 	selector, _ := labels.Parse(fmt.Sprintf("%s=%s", workloadsv1alpha1.SetRBGSetNameLabelKey, rbgset.Name))
@@ -89,9 +112,11 @@ func (r *RoleBasedGroupSetReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	// 3. Calculate the difference between the desired state and the current state to determine which RBGs to create or delete.
-	// Map existing RBGs by their index label for efficient lookup.
+	// Map existing RBGs by their index label for efficient lookup. Draining RBGs are already removed from the set,
+	// but their indices stay occupied until they are deleted.
 	existingRBGs := make(map[int]*workloadsv1alpha1.RoleBasedGroup)
-	var rbgsToDelete []*workloadsv1alpha1.RoleBasedGroup
+	occupiedIndices := sets.New[int]()
+	var rbgsToDelete, drainingRBGs []*workloadsv1alpha1.RoleBasedGroup
 	for i := range rbglist.Items {
 		rbg := &rbglist.Items[i]
 		indexStr, ok := rbg.Labels[workloadsv1alpha1.SetRBGIndexLabelKey]
@@ -106,30 +131,51 @@ func (r *RoleBasedGroupSetReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			rbgsToDelete = append(rbgsToDelete, rbg)
 			continue
 		}
+		occupiedIndices.Insert(index)
+		if _, draining := rbgDrainingSince(rbg); draining {
+			drainingRBGs = append(drainingRBGs, rbg)
+			continue
+		}
 		existingRBGs[index] = rbg
 	}
 
 	desiredReplicas := int(*rbgset.Spec.Replicas)
+	maxUnavailable, maxSurge, partition, err := rollingUpdateParams(rbgset)
+	if err != nil {
+		logger.Error(err, "Invalid rollout strategy")
+		return ctrl.Result{}, err
+	}
+	// Surge RBGs are kept above the desired replicas while the rolling update is in progress.
+	surgeReplicas, err := calculateSurgeReplicas(rbgset, existingRBGs, maxSurge, partition)
+	if err != nil {
+		logger.Error(err, "Failed to calculate surge replicas")
+		return ctrl.Result{}, err
+	}
+	targetReplicas := desiredReplicas + surgeReplicas
 	var rbgsToCreate []*workloadsv1alpha1.RoleBasedGroup
 
-	// Determine which RBGs need to be created.
-	for i := 0; i < desiredReplicas; i++ {
-		if _, exists := existingRBGs[i]; !exists {
-			rbg := newRBGForSet(rbgset, i)
-			rbgsToCreate = append(rbgsToCreate, rbg)
+	// Determine which RBGs need to be created, the lowest free indices are used.
+	for i := 0; len(existingRBGs)+len(rbgsToCreate) < targetReplicas; i++ {
+		if occupiedIndices.Has(i) {
+			continue
+		}
+		rbg, err := newRBGForSet(rbgset, i)
+		if err != nil {
+			logger.Error(err, "Failed to build RoleBasedGroup", "index", i)
+			return ctrl.Result{}, err
 		}
+		rbgsToCreate = append(rbgsToCreate, rbg)
 	}
 
-	// Determine which RBGs need to be deleted (e.g., index is out of bounds of desired replicas).
-	for index, rbg := range existingRBGs {
-		if index >= desiredReplicas {
-			rbgsToDelete = append(rbgsToDelete, rbg)
-		}
+	// Determine which RBGs need to be removed according to the scale strategy.
+	var rbgsToRemove []*workloadsv1alpha1.RoleBasedGroup
+	if len(existingRBGs) > targetReplicas {
+		rbgsToRemove = selectRBGsToScaleDown(rbgset, existingRBGs, len(existingRBGs)-targetReplicas)
 	}
 
 	// 4. Perform scaling operations.
 	if len(rbgsToCreate) > 0 {
-		logger.Info(fmt.Sprintf("Scaling up RoleBasedGroups, %d -> %d", len(existingRBGs), desiredReplicas), "count", len(rbgsToCreate))
+		logger.Info(fmt.Sprintf("Scaling up RoleBasedGroups, %d -> %d", len(existingRBGs), targetReplicas), "count", len(rbgsToCreate))
 		if err := r.scaleUp(ctx, rbgset, rbgsToCreate); err != nil {
 			logger.Error(err, "Failed to scale up")
 			// Returning an error will trigger a requeue.
@@ -137,27 +183,67 @@ func (r *RoleBasedGroupSetReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 	}
 
+	var requeueAfter time.Duration
+	if len(rbgsToRemove) > 0 || len(drainingRBGs) > 0 {
+		if len(rbgsToRemove) > 0 {
+			logger.Info(fmt.Sprintf("Scaling down RoleBasedGroups, %d -> %d", len(existingRBGs), targetReplicas), "count", len(rbgsToRemove))
+			for _, rbg := range rbgsToRemove {
+				delete(existingRBGs, rbgIndex(rbg))
+			}
+		}
+		drained, remaining, err := r.drainRBGs(ctx, rbgset, append(drainingRBGs, rbgsToRemove...))
+		if err != nil {
+			logger.Error(err, "Failed to drain RoleBasedGroups")
+			return ctrl.Result{}, err
+		}
+		rbgsToDelete = append(rbgsToDelete, drained...)
+		requeueAfter = remaining
+	}
+
 	if len(rbgsToDelete) > 0 {
-		logger.Info(fmt.Sprintf("Scaling down RoleBasedGroups, %d -> %d", len(existingRBGs), desiredReplicas), "count", len(rbgsToDelete))
 		if err := r.scaleDown(ctx, rbgsToDelete); err != nil {
 			logger.Error(err, "Failed to scale down")
 			return ctrl.Result{}, err
 		}
 	}
 
-	// 5. Update the status after all operations are complete.
+	// 5. Roll the template out to the existing RBGs.
+	if err := r.rollingUpdate(ctx, rbgset, existingRBGs, maxUnavailable, partition); err != nil {
+		logger.Error(err, "Failed to perform rolling update")
+		return ctrl.Result{}, err
+	}
+
+	// 6. Gang-schedule the RBGs of the set together.
+	minMember, err := podGroupMinMember(rbgset, slices.Sorted(maps.Keys(existingRBGs)), rbgsToCreate)
+	if err != nil {
+		logger.Error(err, "Failed to calculate the size of the PodGroup")
+		return ctrl.Result{}, err
+	}
+	if err := scheduler.NewPodGroupScheduler(r.client).ReconcileRBGSet(ctx, rbgset, minMember); err != nil {
+		logger.Error(err, "Failed to reconcile PodGroup")
+		r.recorder.Event(rbgset, corev1.EventTypeWarning, FailedCreatePodGroup, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	// 7. Update the status after all operations are complete.
 	// After scaling, re-list the children to ensure the status is accurate.
 	if err := r.client.List(ctx, &rbglist, client.InNamespace(rbgset.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
 		logger.Error(err, "Failed to re-list child RoleBasedGroups for status update")
 		return ctrl.Result{}, err
 	}
+	if err := r.reconcileDiscoveryConfig(ctx, rbgset, &rbglist); err != nil {
+		logger.Error(err, "Failed to reconcile the discovery config")
+		r.recorder.Eventf(rbgset, corev1.EventTypeWarning, FailedReconcileDiscovery,
+			"Failed to reconcile the discovery config: %v", err)
+		return ctrl.Result{}, err
+	}
 	if err := r.updateStatus(ctx, rbgset, &rbglist); err != nil {
 		logger.Error(err, "Failed to update RoleBasedGroupSet status")
 		return ctrl.Result{}, err
 	}
 
 	logger.Info("Successfully reconciled rbgset")
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // scaleUp concurrently creates a given set of RoleBasedGroup instances.
@@ -232,22 +318,235 @@ func (r *RoleBasedGroupSetReconciler) scaleDown(ctx context.Context, rbgsToDelet
 	return utilerrors.NewAggregate(allErrs)
 }
 
+// rollingUpdate patches the outdated RBGs with index not less than partition to the latest template in index order.
+// An available RBG is only patched if the number of available RBGs stays above replicas - maxUnavailable, so
+// the rolling update waits for the patched RBGs to become ready before it moves on.
+func (r *RoleBasedGroupSetReconciler) rollingUpdate(
+	ctx context.Context, rbgset *workloadsv1alpha1.RoleBasedGroupSet,
+	existingRBGs map[int]*workloadsv1alpha1.RoleBasedGroup, maxUnavailable, partition int,
+) error {
+	logger := log.FromContext(ctx)
+
+	available := 0
+	for _, rbg := range existingRBGs {
+		if rbgAvailable(rbg) {
+			available++
+		}
+	}
+	// The surge RBGs do not lower the number of RBGs which must stay available.
+	budget := available - (int(*rbgset.Spec.Replicas) - maxUnavailable)
+
+	indices := slices.Sorted(maps.Keys(existingRBGs))
+	for _, i := range indices {
+		rbg := existingRBGs[i]
+		desired, err := newRBGForSet(rbgset, i)
+		if err != nil {
+			return err
+		}
+		if rbgUpdated(rbg, desired) {
+			// the revision changes without changing the spec of the index if e.g. an override of another
+			// index is changed.
+			if err := r.syncRevisionLabels(ctx, rbg, desired); err != nil {
+				return err
+			}
+			continue
+		}
+		if i < partition {
+			continue
+		}
+		if rbgAvailable(rbg) {
+			if budget <= 0 {
+				logger.Info("Waiting for updated RoleBasedGroups to be ready", "next", rbg.Name)
+				return nil
+			}
+			budget--
+		}
+
+		patch := client.MergeFrom(rbg.DeepCopy())
+		rbg.Spec = specKeepingScaledReplicas(rbg, desired)
+		if rbg.Labels == nil {
+			rbg.Labels = map[string]string{}
+		}
+		rbg.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey] = desired.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey]
+		rbg.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey] = desired.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey]
+		if podGroup, ok := desired.Labels[workloadsv1alpha1.PodGroupLabelKey]; ok {
+			rbg.Labels[workloadsv1alpha1.PodGroupLabelKey] = podGroup
+		} else {
+			delete(rbg.Labels, workloadsv1alpha1.PodGroupLabelKey)
+		}
+		if err := r.client.Patch(ctx, rbg, patch); err != nil {
+			return fmt.Errorf("failed to update RoleBasedGroup %s: %w", rbg.Name, err)
+		}
+		logger.Info("Successfully updated RoleBasedGroup", "name", rbg.Name)
+		r.recorder.Eventf(rbgset, corev1.EventTypeNormal, RollingUpdateRBG,
+			"Updated RoleBasedGroup %s to the latest template", rbg.Name)
+	}
+	return nil
+}
+
+// calculateSurgeReplicas returns the number of RBGs which are kept above the desired replicas. Surge RBGs are
+// created while some RBGs with index not less than partition are outdated, and kept until the updated RBGs
+// are available.
+func calculateSurgeReplicas(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, existingRBGs map[int]*workloadsv1alpha1.RoleBasedGroup,
+	maxSurge, partition int,
+) (int, error) {
+	if maxSurge == 0 {
+		return 0, nil
+	}
+	surging := len(existingRBGs) > int(*rbgset.Spec.Replicas)
+
+	outdated, pending := 0, 0
+	for i, rbg := range existingRBGs {
+		if i < partition {
+			continue
+		}
+		desired, err := newRBGForSet(rbgset, i)
+		if err != nil {
+			return 0, err
+		}
+		if !rbgUpdated(rbg, desired) {
+			outdated++
+			pending++
+		} else if !rbgAvailable(rbg) {
+			pending++
+		}
+	}
+	if outdated == 0 && !surging {
+		return 0, nil
+	}
+	return min(maxSurge, pending), nil
+}
+
+// rollingUpdateParams returns the maxUnavailable, maxSurge and partition of the rolling update of rbgset.
+func rollingUpdateParams(rbgset *workloadsv1alpha1.RoleBasedGroupSet) (int, int, int, error) {
+	replicas := int(*rbgset.Spec.Replicas)
+	if rbgset.Spec.RolloutStrategy == nil || rbgset.Spec.RolloutStrategy.RollingUpdate == nil {
+		return 1, 0, 0, nil
+	}
+
+	rollingUpdate := rbgset.Spec.RolloutStrategy.RollingUpdate
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(&rollingUpdate.MaxUnavailable, replicas, false)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	maxSurge, err := intstr.GetScaledValueFromIntOrPercent(&rollingUpdate.MaxSurge, replicas, true)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	// No need to surge more than the replicas.
+	maxSurge = min(maxSurge, replicas)
+	// The update can not make progress if neither unavailable nor surge RBGs are allowed.
+	if maxUnavailable == 0 && maxSurge == 0 {
+		maxUnavailable = 1
+	}
+	partition := min(int(ptr.Deref(rollingUpdate.Partition, 0)), replicas)
+	return maxUnavailable, maxSurge, partition, nil
+}
+
+// rbgUpdated returns true if rbg was last updated to the desired spec and has the desired PodGroup. The spec hash
+// label decides instead of the live spec, so that the replicas of the roles scaled by their scaling adapters do
+// not make a rbg outdated. The rbgs without the label are compared by their spec.
+func rbgUpdated(rbg, desired *workloadsv1alpha1.RoleBasedGroup) bool {
+	if rbg.Labels[workloadsv1alpha1.PodGroupLabelKey] != desired.Labels[workloadsv1alpha1.PodGroupLabelKey] {
+		return false
+	}
+	if specHash, ok := rbg.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey]; ok {
+		return specHash == desired.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey]
+	}
+	return equality.Semantic.DeepEqual(rbg.Spec, specKeepingScaledReplicas(rbg, desired))
+}
+
+// specKeepingScaledReplicas returns the spec of desired with the replicas of the roles with scaling adapter enabled
+// taken from rbg, as they are owned by the scaling adapters of the roles.
+func specKeepingScaledReplicas(rbg, desired *workloadsv1alpha1.RoleBasedGroup) workloadsv1alpha1.RoleBasedGroupSpec {
+	spec := *desired.Spec.DeepCopy()
+	for i := range spec.Roles {
+		role := &spec.Roles[i]
+		if !scale.IsScalingAdapterEnable(role) {
+			continue
+		}
+		for _, current := range rbg.Spec.Roles {
+			if current.Name == role.Name {
+				role.Replicas = current.Replicas
+				break
+			}
+		}
+	}
+	return spec
+}
+
+// rbgAvailable returns true if rbg is ready for its latest generation and all its roles run the latest revision.
+func rbgAvailable(rbg *workloadsv1alpha1.RoleBasedGroup) bool {
+	if rbg.Status.ObservedGeneration != rbg.Generation ||
+		!meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+		return false
+	}
+	for _, roleStatus := range rbg.Status.RoleStatuses {
+		if roleStatus.UpdatedReplicas < roleStatus.Replicas {
+			return false
+		}
+	}
+	return true
+}
+
 // updateStatus updates the status of the RoleBasedGroupSet.
 func (r *RoleBasedGroupSetReconciler) updateStatus(ctx context.Context, rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbglist *workloadsv1alpha1.RoleBasedGroupList) error {
 	logger := log.FromContext(ctx)
 
 	// Create a deep copy of the status to modify.
 	newStatus := *rbgset.Status.DeepCopy()
-	newStatus.Replicas = int32(len(rbglist.Items))
+	newStatus.ObservedGeneration = rbgset.Generation
 
-	// Calculate the number of ready replicas.
-	readyReplicas := 0
-	for _, rbg := range rbglist.Items {
-		if meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+	// Calculate the number of ready and updated replicas.
+	readyReplicas, updatedReplicas, drainingReplicas := 0, 0, 0
+	indexStatuses := []workloadsv1alpha1.RoleBasedGroupSetIndexStatus{}
+	var activeRBGs []*workloadsv1alpha1.RoleBasedGroup
+	for i := range rbglist.Items {
+		rbg := &rbglist.Items[i]
+		// draining RBGs are no longer part of the set
+		if _, draining := rbgDrainingSince(rbg); draining {
+			drainingReplicas++
+			continue
+		}
+		activeRBGs = append(activeRBGs, rbg)
+		ready := meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady))
+		if ready {
 			readyReplicas++
 		}
+		index := rbgIndex(rbg)
+		if index < 0 {
+			continue
+		}
+		desired, err := newRBGForSet(rbgset, index)
+		if err != nil {
+			return err
+		}
+		updated := rbgUpdated(rbg, desired)
+		if updated {
+			updatedReplicas++
+		}
+		indexStatuses = append(indexStatuses, workloadsv1alpha1.RoleBasedGroupSetIndexStatus{
+			Index:    int32(index),
+			Name:     rbg.Name,
+			SpecHash: rbgSpecHash(&desired.Spec),
+			Updated:  updated,
+			Ready:    ready,
+			Revision: rbg.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey],
+		})
 	}
+	slices.SortFunc(indexStatuses, func(a, b workloadsv1alpha1.RoleBasedGroupSetIndexStatus) int {
+		return int(a.Index - b.Index)
+	})
+	newStatus.Replicas = int32(len(activeRBGs))
+	newStatus.Selector = rbgSetPodSelector(rbgset)
 	newStatus.ReadyReplicas = int32(readyReplicas)
+	newStatus.UpdatedReplicas = int32(updatedReplicas)
+	newStatus.IndexStatuses = indexStatuses
+	newStatus.TopologyDistribution = topologyDistribution(rbgset, activeRBGs)
+	newStatus.UpdateRevision = rbgSetRevision(rbgset)
+	newStatus.Revisions = revisionStatuses(activeRBGs)
+	newStatus.RoleStatuses = aggregateRoleStatuses(rbgset, activeRBGs)
 
 	// Update the Condition.
 	desiredReplicas := *rbgset.Spec.Replicas
@@ -271,6 +570,7 @@ func (r *RoleBasedGroupSetReconciler) updateStatus(ctx context.Context, rbgset *
 	}
 	// Use apimeta.SetStatusCondition to safely set or update the condition. It correctly handles the LastTransitionTime.
 	meta.SetStatusCondition(&newStatus.Conditions, condition)
+	setProgressingConditions(&newStatus, desiredReplicas, drainingReplicas)
 
 	// Only update the status if it has changed to avoid unnecessary API calls.
 	if reflect.DeepEqual(rbgset.Status, newStatus) {
@@ -297,22 +597,88 @@ func (r *RoleBasedGroupSetReconciler) updateStatus(ctx context.Context, rbgset *
 	})
 }
 
-// newRBGForSet creates a new RoleBasedGroup object based on the set's template.
-func newRBGForSet(rbgset *workloadsv1alpha1.RoleBasedGroupSet, index int) *workloadsv1alpha1.RoleBasedGroup {
-	return &workloadsv1alpha1.RoleBasedGroup{
+// newRBGForSet creates a new RoleBasedGroup object based on the set's template and the overrides of the index.
+func newRBGForSet(rbgset *workloadsv1alpha1.RoleBasedGroupSet, index int) (*workloadsv1alpha1.RoleBasedGroup, error) {
+	spec, err := rbgSpecForIndex(rbgset, index)
+	if err != nil {
+		return nil, err
+	}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: rbgset.Namespace,
 			Name:      fmt.Sprintf("%s-%d", rbgset.Name, index),
 			Labels: map[string]string{
-				workloadsv1alpha1.SetRBGSetNameLabelKey: rbgset.Name,
-				workloadsv1alpha1.SetRBGIndexLabelKey:   fmt.Sprintf("%d", index),
+				workloadsv1alpha1.SetRBGSetNameLabelKey:  rbgset.Name,
+				workloadsv1alpha1.SetRBGIndexLabelKey:    fmt.Sprintf("%d", index),
+				workloadsv1alpha1.SetRBGRevisionLabelKey: rbgSetRevision(rbgset),
+				workloadsv1alpha1.SetRBGSpecHashLabelKey: rbgSpecHash(&spec),
 			},
 			// The OwnerReference will be set in the scaleUp function.
 		},
-		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
-			Roles: rbgset.Spec.Template.Roles,
-		},
+		Spec: spec,
+	}
+	if rbgset.Spec.PodGroupPolicy != nil {
+		rbg.Labels[workloadsv1alpha1.PodGroupLabelKey] = rbgset.Name
+	}
+	return rbg, nil
+}
+
+// rbgSpecForIndex returns the effective spec of the rbg of index, i.e. the template with its roles pinned to the
+// topology domain of the index, with the overrides of the index applied in order.
+func rbgSpecForIndex(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, index int,
+) (workloadsv1alpha1.RoleBasedGroupSpec, error) {
+	// the whole template is copied, so that the spec of a rbg never shares memory with the template.
+	spec := *rbgset.Spec.Template.DeepCopy()
+	if spread := rbgset.Spec.TopologySpread; spread != nil && len(spread.Values) > 0 {
+		value := topologyValueForIndex(spread, index)
+		for i := range spec.Roles {
+			injectTopologyAffinity(&spec.Roles[i].Template, spread.TopologyKey, value)
+		}
+	}
+	for i, override := range rbgset.Spec.Overrides {
+		if override.Patch.Raw == nil || !overrideAppliesTo(&override, index) {
+			continue
+		}
+		specBytes, err := json.Marshal(spec)
+		if err != nil {
+			return spec, err
+		}
+		patched, err := strategicpatch.StrategicMergePatch(specBytes, override.Patch.Raw, &workloadsv1alpha1.RoleBasedGroupSpec{})
+		if err != nil {
+			return spec, fmt.Errorf("failed to apply override %d to index %d: %w", i, index, err)
+		}
+		spec = workloadsv1alpha1.RoleBasedGroupSpec{}
+		if err := json.Unmarshal(patched, &spec); err != nil {
+			return spec, fmt.Errorf("failed to apply override %d to index %d: %w", i, index, err)
+		}
 	}
+	return spec, nil
+}
+
+// overrideAppliesTo returns true if the override selects index by its indices or index range.
+func overrideAppliesTo(override *workloadsv1alpha1.RoleBasedGroupSetOverride, index int) bool {
+	if slices.Contains(override.Indices, int32(index)) {
+		return true
+	}
+	if r := override.IndexRange; r != nil {
+		return index >= int(r.Start) && (r.End == nil || index <= int(*r.End))
+	}
+	return false
+}
+
+// rbgSpecHash returns the hash of the spec of a rbg.
+func rbgSpecHash(spec *workloadsv1alpha1.RoleBasedGroupSpec) string {
+	return hashObject(spec)
+}
+
+// hashObject returns the hash of the json encoding of obj.
+func hashObject(obj any) string {
+	// the api types only consist of json serializable fields, the error is impossible.
+	specBytes, _ := json.Marshal(obj)
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(specBytes)
+	return rand.SafeEncodeString(strconv.FormatUint(uint64(hasher.Sum32()), 10))
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -321,6 +687,10 @@ func (r *RoleBasedGroupSetReconciler) SetupWithManager(mgr ctrl.Manager, options
 		WithOptions(options).
 		For(&workloadsv1alpha1.RoleBasedGroupSet{}).
 		Owns(&workloadsv1alpha1.RoleBasedGroup{}).
+		Owns(&workloadsv1alpha1.RoleBasedGroupScalingAdapter{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToDiscoveryRBGSet),
+			builder.WithPredicates(DiscoveryPodPredicate())).
 		Named("rbgset-controller").
 		Complete(r)
 }
@@ -28,12 +28,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/rbgs/pkg/utils"
@@ -208,7 +212,9 @@ func TestRoleBasedGroupSetReconciler_scaleUp(t *testing.T) {
 			// We generate this list based on the test case count.
 			var rbgsToCreate []*v1alpha1.RoleBasedGroup
 			for i := 0; i < tt.count; i++ {
-				rbgsToCreate = append(rbgsToCreate, newRBGForSet(rbgset, i))
+				rbg, err := newRBGForSet(rbgset, i)
+				assert.NoError(t, err)
+				rbgsToCreate = append(rbgsToCreate, rbg)
 			}
 
 			err := r.scaleUp(context.Background(), rbgset, rbgsToCreate)
@@ -350,6 +356,7 @@ func TestRoleBasedGroupSetReconciler_scaleDown(t *testing.T) {
 func TestRoleBasedGroupSetReconciler_Reconcile_StatusUpdate(t *testing.T) {
 	// Setup test scheme
 	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
 	_ = v1alpha1.AddToScheme(scheme)
 
 	tests := []struct {
@@ -553,3 +560,607 @@ func TestRoleBasedGroupSetReconciler_Reconcile_StatusUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestRoleBasedGroupSetReconciler_Reconcile_RollingUpdate tests the rolling update of the template within the Reconcile loop.
+func TestRoleBasedGroupSetReconciler_Reconcile_RollingUpdate(t *testing.T) {
+	// Setup test scheme
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	oldTemplate := v1alpha1.RoleBasedGroupSpec{Roles: []v1alpha1.RoleSpec{{Name: "role-1", Replicas: ptr.To(int32(1))}}}
+	newTemplate := v1alpha1.RoleBasedGroupSpec{Roles: []v1alpha1.RoleSpec{{Name: "role-1", Replicas: ptr.To(int32(2))}}}
+
+	buildRBGSet := func(replicas int32, strategy *v1alpha1.RoleBasedGroupSetRollingUpdate) *v1alpha1.RoleBasedGroupSet {
+		rbgset := &v1alpha1.RoleBasedGroupSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+			Spec: v1alpha1.RoleBasedGroupSetSpec{
+				Replicas: ptr.To(replicas),
+				Template: newTemplate,
+			},
+		}
+		if strategy != nil {
+			rbgset.Spec.RolloutStrategy = &v1alpha1.RoleBasedGroupSetRolloutStrategy{
+				Type:          v1alpha1.RollingUpdateStrategyType,
+				RollingUpdate: strategy,
+			}
+		}
+		return rbgset
+	}
+	// buildRBG builds the child rbg of index with the given template, ready is the Ready condition of
+	// its latest generation.
+	buildRBG := func(index int, template v1alpha1.RoleBasedGroupSpec, ready bool) v1alpha1.RoleBasedGroup {
+		status := metav1.ConditionFalse
+		if ready {
+			status = metav1.ConditionTrue
+		}
+		return v1alpha1.RoleBasedGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("test-rbgset-%d", index),
+				Namespace:  "default",
+				Generation: 1,
+				Labels: map[string]string{
+					v1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+					v1alpha1.SetRBGIndexLabelKey:   fmt.Sprintf("%d", index),
+				},
+			},
+			Spec: *template.DeepCopy(),
+			Status: v1alpha1.RoleBasedGroupStatus{
+				ObservedGeneration: 1,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(v1alpha1.RoleBasedGroupReady),
+						Status: status,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		rbgset                *v1alpha1.RoleBasedGroupSet
+		rbgList               []v1alpha1.RoleBasedGroup
+		expectUpdated         []string
+		expectOutdated        []string
+		expectMissing         []string
+		expectUpdatedReplicas int32
+	}{
+		{
+			name:   "Update the first RBG by default",
+			rbgset: buildRBGSet(3, nil),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, oldTemplate, true),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0"},
+			expectOutdated:        []string{"test-rbgset-1", "test-rbgset-2"},
+			expectUpdatedReplicas: 1,
+		},
+		{
+			name:   "Wait for the updated RBG to be ready",
+			rbgset: buildRBGSet(3, nil),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, newTemplate, false),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0"},
+			expectOutdated:        []string{"test-rbgset-1", "test-rbgset-2"},
+			expectUpdatedReplicas: 1,
+		},
+		{
+			name:   "Move on once the updated RBG is ready",
+			rbgset: buildRBGSet(3, nil),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, newTemplate, true),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0", "test-rbgset-1"},
+			expectOutdated:        []string{"test-rbgset-2"},
+			expectUpdatedReplicas: 2,
+		},
+		{
+			name: "Update RBGs up to maxUnavailable at once",
+			rbgset: buildRBGSet(4, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromString("50%"),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, oldTemplate, true),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, oldTemplate, true),
+				buildRBG(3, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0", "test-rbgset-1"},
+			expectOutdated:        []string{"test-rbgset-2", "test-rbgset-3"},
+			expectUpdatedReplicas: 2,
+		},
+		{
+			name: "Only update RBGs with index not less than partition",
+			rbgset: buildRBGSet(3, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(3),
+				Partition:      ptr.To(int32(2)),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, oldTemplate, true),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-2"},
+			expectOutdated:        []string{"test-rbgset-0", "test-rbgset-1"},
+			expectUpdatedReplicas: 1,
+		},
+		{
+			name: "Create surge RBG before updating available RBGs",
+			rbgset: buildRBGSet(2, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(1),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, oldTemplate, true),
+				buildRBG(1, oldTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-2"},
+			expectOutdated:        []string{"test-rbgset-0", "test-rbgset-1"},
+			expectUpdatedReplicas: 1,
+		},
+		{
+			name: "Update RBG once the surge RBG is ready",
+			rbgset: buildRBGSet(2, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(1),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, oldTemplate, true),
+				buildRBG(1, oldTemplate, true),
+				buildRBG(2, newTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0", "test-rbgset-2"},
+			expectOutdated:        []string{"test-rbgset-1"},
+			expectUpdatedReplicas: 2,
+		},
+		{
+			name: "Keep surge RBG until the updated RBGs are ready",
+			rbgset: buildRBGSet(2, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(1),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, newTemplate, true),
+				buildRBG(1, newTemplate, false),
+				buildRBG(2, newTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0", "test-rbgset-1", "test-rbgset-2"},
+			expectUpdatedReplicas: 3,
+		},
+		{
+			name: "Remove surge RBG once the update completes",
+			rbgset: buildRBGSet(2, &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(1),
+			}),
+			rbgList: []v1alpha1.RoleBasedGroup{
+				buildRBG(0, newTemplate, true),
+				buildRBG(1, newTemplate, true),
+				buildRBG(2, newTemplate, true),
+			},
+			expectUpdated:         []string{"test-rbgset-0", "test-rbgset-1"},
+			expectMissing:         []string{"test-rbgset-2"},
+			expectUpdatedReplicas: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []runtime.Object{tt.rbgset}
+			for i := range tt.rbgList {
+				objs = append(objs, &tt.rbgList[i])
+			}
+			r := &RoleBasedGroupSetReconciler{
+				client: fake.NewClientBuilder().WithScheme(scheme).
+					WithRuntimeObjects(objs...).
+					WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+				scheme:   scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: tt.rbgset.Namespace, Name: tt.rbgset.Name},
+			})
+			assert.NoError(t, err)
+
+			for _, name := range tt.expectUpdated {
+				rbg := &v1alpha1.RoleBasedGroup{}
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, rbg))
+				assert.Equal(t, newTemplate, rbg.Spec, "rbg %s should be updated", name)
+			}
+			for _, name := range tt.expectOutdated {
+				rbg := &v1alpha1.RoleBasedGroup{}
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, rbg))
+				assert.Equal(t, oldTemplate, rbg.Spec, "rbg %s should not be updated", name)
+			}
+			for _, name := range tt.expectMissing {
+				rbg := &v1alpha1.RoleBasedGroup{}
+				err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, rbg)
+				assert.True(t, apierrors.IsNotFound(err), "rbg %s should be deleted", name)
+			}
+
+			updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{
+				Name: tt.rbgset.Name, Namespace: tt.rbgset.Namespace,
+			}, updatedRBGSet))
+			assert.Equal(t, tt.expectUpdatedReplicas, updatedRBGSet.Status.UpdatedReplicas)
+		})
+	}
+}
+
+func TestRoleBasedGroupSetReconciler_Reconcile_ScaledRoles(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	buildRBGSet := func(image string) *v1alpha1.RoleBasedGroupSet {
+		return &v1alpha1.RoleBasedGroupSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+			Spec: v1alpha1.RoleBasedGroupSetSpec{
+				Replicas: ptr.To(int32(1)),
+				Template: v1alpha1.RoleBasedGroupSpec{Roles: []v1alpha1.RoleSpec{
+					{
+						Name:           "prefill",
+						Replicas:       ptr.To(int32(1)),
+						ScalingAdapter: &v1alpha1.ScalingAdapter{Enable: true},
+						Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "prefill", Image: image}},
+						}},
+					},
+					{Name: "decode", Replicas: ptr.To(int32(1))},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		rbgset               *v1alpha1.RoleBasedGroupSet
+		image                string
+		expectImage          string
+		expectDecodeReplicas int32
+	}{
+		{
+			// the rbg is up to date, so it is not patched back to the template.
+			name:                 "Keep the replicas scaled by the scaling adapter",
+			rbgset:               buildRBGSet("v1"),
+			image:                "v1",
+			expectImage:          "v1",
+			expectDecodeReplicas: 2,
+		},
+		{
+			name:                 "Keep the replicas scaled by the scaling adapter on update",
+			rbgset:               buildRBGSet("v2"),
+			image:                "v1",
+			expectImage:          "v2",
+			expectDecodeReplicas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg, err := newRBGForSet(buildRBGSet(tt.image), 0)
+			assert.NoError(t, err)
+			rbg.Generation = 1
+			rbg.Status = v1alpha1.RoleBasedGroupStatus{
+				ObservedGeneration: 1,
+				Conditions: []metav1.Condition{
+					{Type: string(v1alpha1.RoleBasedGroupReady), Status: metav1.ConditionTrue},
+				},
+			}
+			// the scaling adapter of prefill scaled it out, decode drifted from the template.
+			rbg.Spec.Roles[0].Replicas = ptr.To(int32(3))
+			rbg.Spec.Roles[1].Replicas = ptr.To(int32(2))
+
+			r := &RoleBasedGroupSetReconciler{
+				client: fake.NewClientBuilder().WithScheme(scheme).
+					WithRuntimeObjects(tt.rbgset, rbg).
+					WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+				scheme:   scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+			_, err = r.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: tt.rbgset.Namespace, Name: tt.rbgset.Name},
+			})
+			assert.NoError(t, err)
+
+			updatedRBG := &v1alpha1.RoleBasedGroup{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: rbg.Name, Namespace: "default"}, updatedRBG))
+			assert.Equal(t, int32(3), *updatedRBG.Spec.Roles[0].Replicas)
+			assert.Equal(t, tt.expectImage, updatedRBG.Spec.Roles[0].Template.Spec.Containers[0].Image)
+			assert.Equal(t, tt.expectDecodeReplicas, *updatedRBG.Spec.Roles[1].Replicas)
+
+			updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{
+				Name: tt.rbgset.Name, Namespace: tt.rbgset.Namespace,
+			}, updatedRBGSet))
+			assert.Len(t, updatedRBGSet.Status.IndexStatuses, 1)
+			assert.True(t, updatedRBGSet.Status.IndexStatuses[0].Updated)
+		})
+	}
+}
+
+func TestRollingUpdateParams(t *testing.T) {
+	tests := []struct {
+		name                 string
+		replicas             int32
+		rollingUpdate        *v1alpha1.RoleBasedGroupSetRollingUpdate
+		expectMaxUnavailable int
+		expectMaxSurge       int
+		expectPartition      int
+	}{
+		{
+			name:                 "Default strategy",
+			replicas:             4,
+			expectMaxUnavailable: 1,
+		},
+		{
+			name:     "Percentages are scaled to the replicas",
+			replicas: 4,
+			rollingUpdate: &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromString("30%"),
+				MaxSurge:       intstr.FromString("30%"),
+			},
+			expectMaxUnavailable: 1,
+			expectMaxSurge:       2,
+		},
+		{
+			name:     "MaxUnavailable falls back to 1 if both are 0",
+			replicas: 4,
+			rollingUpdate: &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(0),
+			},
+			expectMaxUnavailable: 1,
+		},
+		{
+			name:     "MaxSurge and partition are capped by the replicas",
+			replicas: 2,
+			rollingUpdate: &v1alpha1.RoleBasedGroupSetRollingUpdate{
+				MaxUnavailable: intstr.FromInt32(0),
+				MaxSurge:       intstr.FromInt32(5),
+				Partition:      ptr.To(int32(5)),
+			},
+			expectMaxSurge:  2,
+			expectPartition: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := &v1alpha1.RoleBasedGroupSet{
+				Spec: v1alpha1.RoleBasedGroupSetSpec{Replicas: ptr.To(tt.replicas)},
+			}
+			if tt.rollingUpdate != nil {
+				rbgset.Spec.RolloutStrategy = &v1alpha1.RoleBasedGroupSetRolloutStrategy{
+					Type:          v1alpha1.RollingUpdateStrategyType,
+					RollingUpdate: tt.rollingUpdate,
+				}
+			}
+			maxUnavailable, maxSurge, partition, err := rollingUpdateParams(rbgset)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectMaxUnavailable, maxUnavailable)
+			assert.Equal(t, tt.expectMaxSurge, maxSurge)
+			assert.Equal(t, tt.expectPartition, partition)
+		})
+	}
+}
+
+func TestRBGSpecForIndex(t *testing.T) {
+	template := v1alpha1.RoleBasedGroupSpec{
+		Roles: []v1alpha1.RoleSpec{
+			{
+				Name:     "prefill",
+				Replicas: ptr.To(int32(1)),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "engine", Env: []corev1.EnvVar{{Name: "GPU", Value: "h100"}, {Name: "TP", Value: "1"}}},
+						},
+					},
+				},
+			},
+			{Name: "decode", Replicas: ptr.To(int32(2))},
+		},
+		Discovery:        &v1alpha1.DiscoveryPolicy{Source: v1alpha1.PodsDiscoverySource},
+		DisruptionBudget: &v1alpha1.DisruptionBudget{MaxUnavailable: ptr.To(intstr.FromInt32(1))},
+		Expose:           &v1alpha1.ExposeSpec{Role: "prefill", Port: ptr.To(int32(8000))},
+	}
+
+	tests := []struct {
+		name      string
+		overrides []v1alpha1.RoleBasedGroupSetOverride
+		index     int
+		expected  func() v1alpha1.RoleBasedGroupSpec
+		expectErr bool
+	}{
+		{
+			name:     "No override",
+			index:    0,
+			expected: func() v1alpha1.RoleBasedGroupSpec { return *template.DeepCopy() },
+		},
+		{
+			name: "Override keeps the group fields of the template",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{0},
+					Patch: runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"decode","replicas":3}],` +
+						`"discovery":{"addressType":"IP"}}`)},
+				},
+			},
+			index: 0,
+			expected: func() v1alpha1.RoleBasedGroupSpec {
+				spec := template.DeepCopy()
+				spec.Roles[1].Replicas = ptr.To(int32(3))
+				spec.Discovery = &v1alpha1.DiscoveryPolicy{
+					Source: v1alpha1.PodsDiscoverySource, AddressType: v1alpha1.IPDiscoveryAddressType,
+				}
+				return *spec
+			},
+		},
+		{
+			name: "Override replaces the disruption budget of the group",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{1},
+					Patch: runtime.RawExtension{Raw: []byte(
+						`{"disruptionBudget":{"maxUnavailable":null,"minAvailable":"50%"}}`)},
+				},
+			},
+			index: 1,
+			expected: func() v1alpha1.RoleBasedGroupSpec {
+				spec := template.DeepCopy()
+				spec.DisruptionBudget = &v1alpha1.DisruptionBudget{MinAvailable: ptr.To(intstr.FromString("50%"))}
+				return *spec
+			},
+		},
+		{
+			name: "Override changes the exposed port",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{0},
+					Patch:   runtime.RawExtension{Raw: []byte(`{"expose":{"port":8080}}`)},
+				},
+			},
+			index: 0,
+			expected: func() v1alpha1.RoleBasedGroupSpec {
+				spec := template.DeepCopy()
+				spec.Expose.Port = ptr.To(int32(8080))
+				return *spec
+			},
+		},
+		{
+			name: "Override selected by indices merges roles by name",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{1},
+					Patch: runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"prefill","replicas":3,` +
+						`"template":{"spec":{"nodeSelector":{"zone":"b"},` +
+						`"containers":[{"name":"engine","env":[{"name":"GPU","value":"a100"}]}]}}}]}`)},
+				},
+			},
+			index: 1,
+			expected: func() v1alpha1.RoleBasedGroupSpec {
+				spec := template.DeepCopy()
+				spec.Roles[0].Replicas = ptr.To(int32(3))
+				spec.Roles[0].Template.Spec.NodeSelector = map[string]string{"zone": "b"}
+				spec.Roles[0].Template.Spec.Containers[0].Env[0].Value = "a100"
+				return *spec
+			},
+		},
+		{
+			name: "Override does not apply to other indices",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{1},
+					Patch:   runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"decode","replicas":3}]}`)},
+				},
+			},
+			index:    0,
+			expected: func() v1alpha1.RoleBasedGroupSpec { return *template.DeepCopy() },
+		},
+		{
+			name: "Later override in index range wins",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					IndexRange: &v1alpha1.IndexRange{Start: 1},
+					Patch:      runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"decode","replicas":3}]}`)},
+				},
+				{
+					IndexRange: &v1alpha1.IndexRange{Start: 2, End: ptr.To(int32(3))},
+					Patch:      runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"decode","replicas":4}]}`)},
+				},
+			},
+			index: 3,
+			expected: func() v1alpha1.RoleBasedGroupSpec {
+				spec := template.DeepCopy()
+				spec.Roles[1].Replicas = ptr.To(int32(4))
+				return *spec
+			},
+		},
+		{
+			name: "Invalid patch",
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{0},
+					Patch:   runtime.RawExtension{Raw: []byte(`{"roles":"invalid"}`)},
+				},
+			},
+			index:     0,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := &v1alpha1.RoleBasedGroupSet{
+				Spec: v1alpha1.RoleBasedGroupSetSpec{Template: *template.DeepCopy(), Overrides: tt.overrides},
+			}
+			spec, err := rbgSpecForIndex(rbgset, tt.index)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, equality.Semantic.DeepEqual(tt.expected(), spec), "unexpected spec %+v", spec)
+		})
+	}
+}
+
+func TestRoleBasedGroupSetReconciler_Reconcile_Overrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			Replicas: ptr.To(int32(2)),
+			Template: v1alpha1.RoleBasedGroupSpec{Roles: []v1alpha1.RoleSpec{{Name: "role-1", Replicas: ptr.To(int32(1))}}},
+			Overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{
+					Indices: []int32{1},
+					Patch:   runtime.RawExtension{Raw: []byte(`{"roles":[{"name":"role-1","replicas":2}]}`)},
+				},
+			},
+		},
+	}
+	r := &RoleBasedGroupSetReconciler{
+		client: fake.NewClientBuilder().WithScheme(scheme).
+			WithRuntimeObjects(rbgset).
+			WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+		scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	_, err := r.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: rbgset.Namespace, Name: rbgset.Name},
+	})
+	assert.NoError(t, err)
+
+	for index, replicas := range []int32{1, 2} {
+		rbg := &v1alpha1.RoleBasedGroup{}
+		assert.NoError(t, r.client.Get(context.TODO(),
+			types.NamespacedName{Name: fmt.Sprintf("test-rbgset-%d", index), Namespace: "default"}, rbg))
+		assert.Equal(t, replicas, *rbg.Spec.Roles[0].Replicas)
+	}
+
+	updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: rbgset.Name, Namespace: rbgset.Namespace}, updatedRBGSet))
+	assert.Len(t, updatedRBGSet.Status.IndexStatuses, 2)
+	for i, status := range updatedRBGSet.Status.IndexStatuses {
+		assert.Equal(t, int32(i), status.Index)
+		assert.Equal(t, fmt.Sprintf("test-rbgset-%d", i), status.Name)
+		assert.True(t, status.Updated)
+		assert.NotEmpty(t, status.SpecHash)
+	}
+	assert.NotEqual(t, updatedRBGSet.Status.IndexStatuses[0].SpecHash, updatedRBGSet.Status.IndexStatuses[1].SpecHash)
+}
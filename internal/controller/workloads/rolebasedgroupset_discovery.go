@@ -0,0 +1,113 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/discovery"
+)
+
+// reconcileDiscoveryConfig publishes the aggregated discovery config of rbgset, listing the roles and instances of
+// its rbgs, if any role of the rbgs mounts it. The rbgs being drained are not listed anymore.
+func (r *RoleBasedGroupSetReconciler) reconcileDiscoveryConfig(
+	ctx context.Context, rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbglist *workloadsv1alpha1.RoleBasedGroupList,
+) error {
+	logger := log.FromContext(ctx)
+
+	var rbgs []workloadsv1alpha1.RoleBasedGroup
+	mounted, discoverPods := false, false
+	for _, rbg := range rbglist.Items {
+		if _, draining := rbgDrainingSince(&rbg); draining || rbg.DeletionTimestamp != nil {
+			continue
+		}
+		rbgs = append(rbgs, rbg)
+		discoverPods = discoverPods || rbg.DiscoverPods()
+		for _, role := range rbg.Spec.Roles {
+			mounted = mounted || role.MountSetDiscoveryConfig()
+		}
+	}
+
+	key := types.NamespacedName{Name: discovery.SetConfigMapName(rbgset.Name), Namespace: rbgset.Namespace}
+	oldCM := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, key, oldCM); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		oldCM = nil
+	}
+
+	if !mounted {
+		if oldCM != nil && metav1.IsControlledBy(oldCM, rbgset) {
+			logger.Info("delete the discovery config of rbgset", "configmap", key.Name)
+			return client.IgnoreNotFound(r.client.Delete(ctx, oldCM))
+		}
+		return nil
+	}
+
+	var pods []corev1.Pod
+	if discoverPods {
+		podList := &corev1.PodList{}
+		if err := r.client.List(ctx, podList, client.InNamespace(rbgset.Namespace),
+			client.MatchingLabels{workloadsv1alpha1.SetRBGSetNameLabelKey: rbgset.Name},
+		); err != nil {
+			return err
+		}
+		pods = podList.Items
+	}
+	data, err := discovery.NewSetConfigBuilder(rbgset, rbgs, pods).Build()
+	if err != nil {
+		return err
+	}
+	desired := map[string]string{discovery.SetConfigKey: string(data)}
+
+	if oldCM == nil {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{workloadsv1alpha1.SetRBGSetNameLabelKey: rbgset.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(rbgset, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroupSet")),
+				},
+			},
+			Data: desired,
+		}
+		logger.Info("create the discovery config of rbgset", "configmap", key.Name)
+		return r.client.Create(ctx, cm)
+	}
+	if !metav1.IsControlledBy(oldCM, rbgset) {
+		return fmt.Errorf("configmap %s exists and is not controlled by rbgset %s", key.Name, rbgset.Name)
+	}
+	if maps.Equal(oldCM.Data, desired) {
+		return nil
+	}
+	oldCM.Data = desired
+	return r.client.Update(ctx, oldCM)
+}
+
+// podToDiscoveryRBGSet enqueues the rbgset of a pod if the rbg of the pod discovers pods, so that the aggregated
+// discovery config of the rbgset is rebuilt.
+func (r *RoleBasedGroupSetReconciler) podToDiscoveryRBGSet(ctx context.Context, obj client.Object) []reconcile.Request {
+	rbgsetName, ok := obj.GetLabels()[workloadsv1alpha1.SetRBGSetNameLabelKey]
+	if !ok {
+		return nil
+	}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{}
+	rbgName := obj.GetLabels()[workloadsv1alpha1.SetNameLabelKey]
+	if err := r.client.Get(ctx, types.NamespacedName{Name: rbgName, Namespace: obj.GetNamespace()}, rbg); err != nil {
+		return nil
+	}
+	if !rbg.DiscoverPods() {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: rbgsetName, Namespace: obj.GetNamespace()}}}
+}
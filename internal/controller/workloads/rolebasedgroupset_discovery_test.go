@@ -0,0 +1,111 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/discovery"
+)
+
+func TestRoleBasedGroupSetReconciler_reconcileDiscoveryConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "rbgset-uid"},
+	}
+	buildRBG := func(index string, setConfig bool) v1alpha1.RoleBasedGroup {
+		router := v1alpha1.RoleSpec{Name: "router", Replicas: ptr.To(int32(1))}
+		if setConfig {
+			router.Discovery = &v1alpha1.RoleDiscoveryConfig{SetConfig: &v1alpha1.SetDiscoveryConfig{}}
+		}
+		return v1alpha1.RoleBasedGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-rbgset-" + index,
+				Namespace: "default",
+				Labels: map[string]string{
+					v1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+					v1alpha1.SetRBGIndexLabelKey:   index,
+				},
+			},
+			Spec: v1alpha1.RoleBasedGroupSpec{
+				Roles: []v1alpha1.RoleSpec{router, {Name: "prefill", Replicas: ptr.To(int32(2))}},
+			},
+		}
+	}
+	draining := buildRBG("2", true)
+	draining.Annotations = map[string]string{v1alpha1.DrainingAnnotationKey: time.Now().Format(time.RFC3339)}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rbgset).Build()
+	r := &RoleBasedGroupSetReconciler{client: c, scheme: scheme}
+	getCM := func() (*corev1.ConfigMap, error) {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(context.TODO(),
+			types.NamespacedName{Name: discovery.SetConfigMapName("test-rbgset"), Namespace: "default"}, cm)
+		return cm, err
+	}
+
+	// no role mounts the config
+	err := r.reconcileDiscoveryConfig(context.TODO(), rbgset, &v1alpha1.RoleBasedGroupList{
+		Items: []v1alpha1.RoleBasedGroup{buildRBG("0", false)},
+	})
+	assert.NoError(t, err)
+	_, err = getCM()
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// the config lists the rbgs of the set except the draining ones
+	err = r.reconcileDiscoveryConfig(context.TODO(), rbgset, &v1alpha1.RoleBasedGroupList{
+		Items: []v1alpha1.RoleBasedGroup{buildRBG("0", true), buildRBG("1", true), draining},
+	})
+	assert.NoError(t, err)
+	cm, err := getCM()
+	assert.NoError(t, err)
+	assert.True(t, metav1.IsControlledBy(cm, rbgset))
+	config := cm.Data[discovery.SetConfigKey]
+	assert.Contains(t, config, "name: test-rbgset-0")
+	assert.Contains(t, config, "name: test-rbgset-1")
+	assert.NotContains(t, config, "name: test-rbgset-2")
+
+	// the config is updated when the set is scaled
+	err = r.reconcileDiscoveryConfig(context.TODO(), rbgset, &v1alpha1.RoleBasedGroupList{
+		Items: []v1alpha1.RoleBasedGroup{buildRBG("0", true)},
+	})
+	assert.NoError(t, err)
+	cm, err = getCM()
+	assert.NoError(t, err)
+	assert.NotContains(t, cm.Data[discovery.SetConfigKey], "name: test-rbgset-1")
+
+	// the config is deleted once no role mounts it
+	err = r.reconcileDiscoveryConfig(context.TODO(), rbgset, &v1alpha1.RoleBasedGroupList{
+		Items: []v1alpha1.RoleBasedGroup{buildRBG("0", false)},
+	})
+	assert.NoError(t, err)
+	_, err = getCM()
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// a configmap of the same name not controlled by the rbgset is left untouched
+	userCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: discovery.SetConfigMapName("test-rbgset"), Namespace: "default"},
+		Data:       map[string]string{"user": "data"},
+	}
+	assert.NoError(t, c.Create(context.TODO(), userCM))
+	err = r.reconcileDiscoveryConfig(context.TODO(), rbgset, &v1alpha1.RoleBasedGroupList{
+		Items: []v1alpha1.RoleBasedGroup{buildRBG("0", true)},
+	})
+	assert.ErrorContains(t, err, "not controlled by rbgset")
+	cm, err = getCM()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"user": "data"}, cm.Data)
+}
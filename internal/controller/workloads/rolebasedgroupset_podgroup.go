@@ -0,0 +1,35 @@
+package workloads
+
+import (
+	"slices"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// podGroupMinMember returns the min members of the PodGroup of rbgset, i.e. the total group size of the rbgs
+// of the lowest replicas indices among the existing and the created rbgs. The surge rbgs of a rolling update
+// are not required to be scheduled together with the others.
+func podGroupMinMember(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, existingIndices []int, rbgsToCreate []*workloadsv1alpha1.RoleBasedGroup,
+) (int32, error) {
+	if rbgset.Spec.PodGroupPolicy == nil {
+		return 0, nil
+	}
+
+	indices := slices.Clone(existingIndices)
+	for _, rbg := range rbgsToCreate {
+		indices = append(indices, rbgIndex(rbg))
+	}
+	slices.Sort(indices)
+	indices = indices[:min(len(indices), int(*rbgset.Spec.Replicas))]
+
+	minMember := 0
+	for _, index := range indices {
+		desired, err := newRBGForSet(rbgset, index)
+		if err != nil {
+			return 0, err
+		}
+		minMember += desired.GetGroupSize()
+	}
+	return int32(minMember), nil
+}
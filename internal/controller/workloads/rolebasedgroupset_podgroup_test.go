@@ -0,0 +1,155 @@
+package workloads
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func buildGangRBGSet(replicas int32) *v1alpha1.RoleBasedGroupSet {
+	return &v1alpha1.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			Replicas: ptr.To(replicas),
+			Template: v1alpha1.RoleBasedGroupSpec{
+				Roles: []v1alpha1.RoleSpec{
+					{Name: "prefill", Replicas: ptr.To(int32(1))},
+					{Name: "decode", Replicas: ptr.To(int32(2))},
+				},
+			},
+			PodGroupPolicy: &v1alpha1.PodGroupPolicy{
+				PodGroupPolicySource: v1alpha1.PodGroupPolicySource{
+					KubeScheduling: &v1alpha1.KubeSchedulingPodGroupPolicySource{
+						ScheduleTimeoutSeconds: ptr.To(int32(60)),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPodGroupMinMember(t *testing.T) {
+	patch, _ := json.Marshal(map[string]any{
+		"roles": []map[string]any{{"name": "decode", "replicas": 4}},
+	})
+
+	tests := []struct {
+		name            string
+		replicas        int32
+		overrides       []v1alpha1.RoleBasedGroupSetOverride
+		noPolicy        bool
+		existingIndices []int
+		createIndices   []int
+		expected        int32
+	}{
+		{
+			name:     "No pod group policy",
+			replicas: 2,
+			noPolicy: true,
+			expected: 0,
+		},
+		{
+			name:            "Group size times replicas",
+			replicas:        2,
+			existingIndices: []int{0},
+			createIndices:   []int{1},
+			expected:        6,
+		},
+		{
+			name:            "Surge rbgs are not counted",
+			replicas:        2,
+			existingIndices: []int{0, 1, 2},
+			expected:        6,
+		},
+		{
+			name:     "Overrides change the group size of an index",
+			replicas: 2,
+			overrides: []v1alpha1.RoleBasedGroupSetOverride{
+				{Indices: []int32{1}, Patch: runtime.RawExtension{Raw: patch}},
+			},
+			existingIndices: []int{0, 1},
+			expected:        8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := buildGangRBGSet(tt.replicas)
+			rbgset.Spec.Overrides = tt.overrides
+			if tt.noPolicy {
+				rbgset.Spec.PodGroupPolicy = nil
+			}
+			var rbgsToCreate []*v1alpha1.RoleBasedGroup
+			for _, i := range tt.createIndices {
+				rbg, err := newRBGForSet(rbgset, i)
+				assert.NoError(t, err)
+				rbgsToCreate = append(rbgsToCreate, rbg)
+			}
+
+			minMember, err := podGroupMinMember(rbgset, tt.existingIndices, rbgsToCreate)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, minMember)
+		})
+	}
+}
+
+func TestRoleBasedGroupSetReconciler_Reconcile_PodGroup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	_ = schedv1alpha1.AddToScheme(scheme)
+
+	rbgset := buildGangRBGSet(2)
+	r := &RoleBasedGroupSetReconciler{
+		client: fake.NewClientBuilder().WithScheme(scheme).
+			WithRuntimeObjects(rbgset).
+			WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+		scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: rbgset.Namespace, Name: rbgset.Name}}
+
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+
+	podGroup := &schedv1alpha1.PodGroup{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset", Namespace: "default"}, podGroup))
+	assert.Equal(t, int32(6), podGroup.Spec.MinMember)
+	assert.Equal(t, ptr.To(int32(60)), podGroup.Spec.ScheduleTimeoutSeconds)
+	assert.True(t, metav1.IsControlledBy(podGroup, rbgset))
+
+	rbglist := &v1alpha1.RoleBasedGroupList{}
+	assert.NoError(t, r.client.List(context.TODO(), rbglist))
+	assert.Len(t, rbglist.Items, 2)
+	for _, rbg := range rbglist.Items {
+		assert.Equal(t, "test-rbgset", rbg.Labels[v1alpha1.PodGroupLabelKey])
+		assert.Nil(t, rbg.Spec.PodGroupPolicy)
+	}
+
+	// disable gang scheduling, the PodGroup is deleted and the rbgs are updated one by one.
+	latest := &v1alpha1.RoleBasedGroupSet{}
+	assert.NoError(t, r.client.Get(context.TODO(), request.NamespacedName, latest))
+	latest.Spec.PodGroupPolicy = nil
+	assert.NoError(t, r.client.Update(context.TODO(), latest))
+
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset", Namespace: "default"}, podGroup)
+	assert.Error(t, err)
+
+	rbg0 := &v1alpha1.RoleBasedGroup{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset-0", Namespace: "default"}, rbg0))
+	assert.NotContains(t, rbg0.Labels, v1alpha1.PodGroupLabelKey)
+}
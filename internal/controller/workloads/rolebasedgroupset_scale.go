@@ -0,0 +1,69 @@
+package workloads
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/scale"
+)
+
+// rbgSetPodSelector returns the label selector of the pods of all rbgs of rbgset, which is exposed by the
+// scale subresource of rbgset and of its scaling adapter.
+func rbgSetPodSelector(rbgset *workloadsv1alpha1.RoleBasedGroupSet) string {
+	return labels.SelectorFromSet(labels.Set{workloadsv1alpha1.SetRBGSetNameLabelKey: rbgset.Name}).String()
+}
+
+// reconcileScalingAdapter creates the scaling adapter of rbgset if it is enabled, and deletes it if it is disabled.
+func (r *RoleBasedGroupSetReconciler) reconcileScalingAdapter(
+	ctx context.Context, rbgset *workloadsv1alpha1.RoleBasedGroupSet,
+) error {
+	logger := log.FromContext(ctx)
+	enabled := rbgset.Spec.ScalingAdapter != nil && rbgset.Spec.ScalingAdapter.Enable
+	adapterName := scale.GenerateSetScalingAdapterName(rbgset.Name)
+
+	adapter := &workloadsv1alpha1.RoleBasedGroupScalingAdapter{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: adapterName, Namespace: rbgset.Namespace}, adapter)
+	if err == nil {
+		if !scale.IsScalingAdapterManagedByRBGSet(adapter, rbgset) {
+			return nil
+		}
+		// the adapter is deleted when rbgset.spec.scalingAdapter.enable is updated to false
+		if !enabled {
+			logger.Info("delete scalingAdapter", "scalingAdapter", adapter.Name)
+			return r.client.Delete(ctx, adapter)
+		}
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	adapter = &workloadsv1alpha1.RoleBasedGroupScalingAdapter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      adapterName,
+			Namespace: rbgset.Namespace,
+			Labels: map[string]string{
+				workloadsv1alpha1.SetRBGSetNameLabelKey: rbgset.Name,
+			},
+		},
+		Spec: workloadsv1alpha1.RoleBasedGroupScalingAdapterSpec{
+			ScaleTargetRef: &workloadsv1alpha1.AdapterScaleTargetRef{
+				Kind: workloadsv1alpha1.AdapterScaleTargetRBGSet,
+				Name: rbgset.Name,
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(rbgset, adapter, r.scheme); err != nil {
+		return err
+	}
+	logger.Info("create scalingAdapter", "scalingAdapter", adapter.Name)
+	return r.client.Create(ctx, adapter)
+}
@@ -0,0 +1,100 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestRoleBasedGroupSetReconciler_Reconcile_ScalingAdapter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	tests := []struct {
+		name           string
+		scalingAdapter *v1alpha1.ScalingAdapter
+		existing       bool
+		expectAdapter  bool
+	}{
+		{
+			name:          "No scaling adapter by default",
+			expectAdapter: false,
+		},
+		{
+			name:           "Create the scaling adapter",
+			scalingAdapter: &v1alpha1.ScalingAdapter{Enable: true},
+			expectAdapter:  true,
+		},
+		{
+			name:           "Delete the disabled scaling adapter",
+			scalingAdapter: &v1alpha1.ScalingAdapter{Enable: false},
+			existing:       true,
+			expectAdapter:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := &v1alpha1.RoleBasedGroupSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+				Spec: v1alpha1.RoleBasedGroupSetSpec{
+					Replicas:       ptr.To(int32(1)),
+					ScalingAdapter: tt.scalingAdapter,
+				},
+			}
+			objs := []runtime.Object{rbgset}
+			if tt.existing {
+				objs = append(objs, &v1alpha1.RoleBasedGroupScalingAdapter{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "test-rbgset.scaling-adapter",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{UID: rbgset.UID}},
+					},
+				})
+			}
+			r := &RoleBasedGroupSetReconciler{
+				client: fake.NewClientBuilder().WithScheme(scheme).
+					WithRuntimeObjects(objs...).
+					WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+				scheme:   scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: rbgset.Namespace, Name: rbgset.Name},
+			})
+			assert.NoError(t, err)
+
+			adapter := &v1alpha1.RoleBasedGroupScalingAdapter{}
+			err = r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset.scaling-adapter", Namespace: "default"}, adapter)
+			if !tt.expectAdapter {
+				assert.True(t, apierrors.IsNotFound(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &v1alpha1.AdapterScaleTargetRef{
+					Kind: v1alpha1.AdapterScaleTargetRBGSet,
+					Name: "test-rbgset",
+				}, adapter.Spec.ScaleTargetRef)
+				assert.Equal(t, "test-rbgset", adapter.Labels[v1alpha1.SetRBGSetNameLabelKey])
+				assert.Len(t, adapter.OwnerReferences, 1)
+				assert.Equal(t, rbgset.UID, adapter.OwnerReferences[0].UID)
+			}
+
+			updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: rbgset.Name, Namespace: rbgset.Namespace}, updatedRBGSet))
+			assert.Equal(t, "rolebasedgroupset.workloads.x-k8s.io/name=test-rbgset", updatedRBGSet.Status.Selector)
+		})
+	}
+}
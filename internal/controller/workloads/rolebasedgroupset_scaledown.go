@@ -0,0 +1,188 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/utils"
+)
+
+// defaultDrainGracePeriodSeconds is used if the drain policy of a rbgset does not set the grace period.
+const defaultDrainGracePeriodSeconds int32 = 30
+
+// selectRBGsToScaleDown selects count rbgs to remove from the existing rbgs of rbgset. The rbgs listed in
+// indicesToDelete go first, then the scale down policy decides, then the rbgs with a lower deletion cost,
+// and finally the rbgs with a higher index.
+func selectRBGsToScaleDown(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, existingRBGs map[int]*workloadsv1alpha1.RoleBasedGroup, count int,
+) []*workloadsv1alpha1.RoleBasedGroup {
+	policy := workloadsv1alpha1.HighestIndexFirst
+	var indicesToDelete []int32
+	if strategy := rbgset.Spec.ScaleStrategy; strategy != nil {
+		if strategy.ScaleDownPolicy != "" {
+			policy = strategy.ScaleDownPolicy
+		}
+		indicesToDelete = strategy.IndicesToDelete
+	}
+
+	candidates := make([]*workloadsv1alpha1.RoleBasedGroup, 0, len(existingRBGs))
+	for _, rbg := range existingRBGs {
+		candidates = append(candidates, rbg)
+	}
+
+	// rank returns the preference keys of a rbg, the rbgs with lower keys are removed first.
+	rank := func(rbg *workloadsv1alpha1.RoleBasedGroup) []int {
+		index := rbgIndex(rbg)
+		keys := make([]int, 0, 4)
+		if slices.Contains(indicesToDelete, int32(index)) {
+			keys = append(keys, 0)
+		} else {
+			keys = append(keys, 1)
+		}
+		if policy == workloadsv1alpha1.NotReadyFirst &&
+			meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+			keys = append(keys, 1)
+		} else {
+			keys = append(keys, 0)
+		}
+		return append(keys, rbgDeletionCost(rbg), -index)
+	}
+	slices.SortFunc(candidates, func(a, b *workloadsv1alpha1.RoleBasedGroup) int {
+		return slices.Compare(rank(a), rank(b))
+	})
+
+	return candidates[:min(count, len(candidates))]
+}
+
+// drainRBGs drains the rbgs removed from rbgset. The rbgs which are not draining yet are marked with the draining
+// annotation, and the routing labels are removed from their pods. The rbgs whose grace period expired are returned
+// to be deleted, together with the time until the earliest grace period of the others expires.
+func (r *RoleBasedGroupSetReconciler) drainRBGs(
+	ctx context.Context, rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbgs []*workloadsv1alpha1.RoleBasedGroup,
+) ([]*workloadsv1alpha1.RoleBasedGroup, time.Duration, error) {
+	var drain *workloadsv1alpha1.RoleBasedGroupSetDrainPolicy
+	if rbgset.Spec.ScaleStrategy != nil {
+		drain = rbgset.Spec.ScaleStrategy.Drain
+	}
+	// rbgs are deleted immediately if the drain phase is disabled, including the ones which started draining
+	// before the drain policy was removed.
+	if drain == nil {
+		return rbgs, 0, nil
+	}
+
+	logger := log.FromContext(ctx)
+	grace := time.Duration(ptr.Deref(drain.GracePeriodSeconds, defaultDrainGracePeriodSeconds)) * time.Second
+	now := time.Now()
+
+	var drained []*workloadsv1alpha1.RoleBasedGroup
+	var requeueAfter time.Duration
+	for _, rbg := range rbgs {
+		since, draining := rbgDrainingSince(rbg)
+		if !draining {
+			since = now
+			patch := client.MergeFrom(rbg.DeepCopy())
+			if rbg.Annotations == nil {
+				rbg.Annotations = map[string]string{}
+			}
+			rbg.Annotations[workloadsv1alpha1.DrainingAnnotationKey] = since.Format(time.RFC3339)
+			if err := r.client.Patch(ctx, rbg, patch); err != nil {
+				return nil, 0, fmt.Errorf("failed to mark RoleBasedGroup %s as draining: %w", rbg.Name, err)
+			}
+			logger.Info("Start draining RoleBasedGroup", "name", rbg.Name, "gracePeriod", grace)
+			r.recorder.Eventf(rbgset, corev1.EventTypeNormal, DrainingRBG,
+				"Draining RoleBasedGroup %s for %s before deletion", rbg.Name, grace)
+		}
+
+		// pods recreated during the drain phase get the routing labels again, so they are removed on every round.
+		if err := r.removeRoutingLabels(ctx, rbg, drain.RoutingLabels); err != nil {
+			return nil, 0, err
+		}
+
+		if remaining := since.Add(grace).Sub(now); remaining > 0 {
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+			continue
+		}
+		drained = append(drained, rbg)
+	}
+	return drained, requeueAfter, nil
+}
+
+// removeRoutingLabels removes the routing labels from the pods of rbg.
+func (r *RoleBasedGroupSetReconciler) removeRoutingLabels(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, routingLabels []string,
+) error {
+	if len(routingLabels) == 0 {
+		return nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name}); err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if utils.PodDeleted(pod) {
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		changed := false
+		for _, key := range routingLabels {
+			if _, ok := pod.Labels[key]; ok {
+				delete(pod.Labels, key)
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := r.client.Patch(ctx, pod, patch); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to remove routing labels from pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// rbgDrainingSince returns the time when rbg started draining, the second return value is false if rbg
+// is not draining.
+func rbgDrainingSince(rbg *workloadsv1alpha1.RoleBasedGroup) (time.Time, bool) {
+	value, ok := rbg.Annotations[workloadsv1alpha1.DrainingAnnotationKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// an invalid value does not block the deletion
+		return time.Time{}, true
+	}
+	return since, true
+}
+
+// rbgDeletionCost returns the deletion cost of rbg, 0 if the annotation is not set or invalid.
+func rbgDeletionCost(rbg *workloadsv1alpha1.RoleBasedGroup) int {
+	cost, err := strconv.ParseInt(rbg.Annotations[workloadsv1alpha1.DeletionCostAnnotationKey], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int(cost)
+}
+
+// rbgIndex returns the index of rbg within its rbgset, -1 if the index label is invalid.
+func rbgIndex(rbg *workloadsv1alpha1.RoleBasedGroup) int {
+	index, err := strconv.Atoi(rbg.Labels[workloadsv1alpha1.SetRBGIndexLabelKey])
+	if err != nil {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,276 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// buildSetRBG builds the child rbg of index of the rbgset "test-rbgset".
+func buildSetRBG(index int, ready bool, annotations map[string]string) *v1alpha1.RoleBasedGroup {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	return &v1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("test-rbgset-%d", index),
+			Namespace:   "default",
+			Annotations: annotations,
+			Labels: map[string]string{
+				v1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+				v1alpha1.SetRBGIndexLabelKey:   fmt.Sprintf("%d", index),
+			},
+		},
+		Status: v1alpha1.RoleBasedGroupStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   string(v1alpha1.RoleBasedGroupReady),
+					Status: status,
+				},
+			},
+		},
+	}
+}
+
+func TestSelectRBGsToScaleDown(t *testing.T) {
+	tests := []struct {
+		name          string
+		scaleStrategy *v1alpha1.RoleBasedGroupSetScaleStrategy
+		rbgs          []*v1alpha1.RoleBasedGroup
+		count         int
+		expected      []string
+	}{
+		{
+			name: "Highest index first by default",
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, false, nil),
+				buildSetRBG(2, true, nil),
+			},
+			count:    2,
+			expected: []string{"test-rbgset-2", "test-rbgset-1"},
+		},
+		{
+			name: "Not ready first",
+			scaleStrategy: &v1alpha1.RoleBasedGroupSetScaleStrategy{
+				ScaleDownPolicy: v1alpha1.NotReadyFirst,
+			},
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, false, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, nil),
+			},
+			count:    2,
+			expected: []string{"test-rbgset-0", "test-rbgset-2"},
+		},
+		{
+			name: "Lower deletion cost first",
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, map[string]string{v1alpha1.DeletionCostAnnotationKey: "-10"}),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, map[string]string{v1alpha1.DeletionCostAnnotationKey: "10"}),
+			},
+			count:    2,
+			expected: []string{"test-rbgset-0", "test-rbgset-1"},
+		},
+		{
+			name: "Indices to delete go first",
+			scaleStrategy: &v1alpha1.RoleBasedGroupSetScaleStrategy{
+				ScaleDownPolicy: v1alpha1.NotReadyFirst,
+				IndicesToDelete: []int32{1},
+			},
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, false, nil),
+			},
+			count:    2,
+			expected: []string{"test-rbgset-1", "test-rbgset-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := &v1alpha1.RoleBasedGroupSet{
+				Spec: v1alpha1.RoleBasedGroupSetSpec{ScaleStrategy: tt.scaleStrategy},
+			}
+			existingRBGs := make(map[int]*v1alpha1.RoleBasedGroup)
+			for _, rbg := range tt.rbgs {
+				existingRBGs[rbgIndex(rbg)] = rbg
+			}
+
+			selected := selectRBGsToScaleDown(rbgset, existingRBGs, tt.count)
+			names := make([]string, 0, len(selected))
+			for _, rbg := range selected {
+				names = append(names, rbg.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestRoleBasedGroupSetReconciler_Reconcile_ScaleDown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	now := time.Now().Truncate(time.Second)
+	drain := &v1alpha1.RoleBasedGroupSetDrainPolicy{
+		GracePeriodSeconds: ptr.To(int32(60)),
+		RoutingLabels:      []string{"serving"},
+	}
+
+	tests := []struct {
+		name             string
+		drain            *v1alpha1.RoleBasedGroupSetDrainPolicy
+		rbgs             []*v1alpha1.RoleBasedGroup
+		expectDeleted    []string
+		expectDraining   []string
+		expectCreated    []string
+		expectRequeue    bool
+		expectReplicas   int32
+		expectPodLabeled bool
+	}{
+		{
+			name: "Delete immediately without drain policy",
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, nil),
+			},
+			expectDeleted:    []string{"test-rbgset-2"},
+			expectReplicas:   2,
+			expectPodLabeled: true,
+		},
+		{
+			name:  "Start draining the removed RBG",
+			drain: drain,
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, nil),
+			},
+			expectDraining: []string{"test-rbgset-2"},
+			expectRequeue:  true,
+			expectReplicas: 2,
+		},
+		{
+			name:  "Keep draining within the grace period",
+			drain: drain,
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, map[string]string{
+					v1alpha1.DrainingAnnotationKey: now.Add(-30 * time.Second).Format(time.RFC3339),
+				}),
+			},
+			expectDraining: []string{"test-rbgset-2"},
+			expectRequeue:  true,
+			expectReplicas: 2,
+		},
+		{
+			name:  "Delete the drained RBG after the grace period",
+			drain: drain,
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, nil),
+				buildSetRBG(2, true, map[string]string{
+					v1alpha1.DrainingAnnotationKey: now.Add(-60 * time.Second).Format(time.RFC3339),
+				}),
+			},
+			expectDeleted:  []string{"test-rbgset-2"},
+			expectReplicas: 2,
+		},
+		{
+			name:  "Replace the draining RBG on scale up with a free index",
+			drain: drain,
+			rbgs: []*v1alpha1.RoleBasedGroup{
+				buildSetRBG(0, true, nil),
+				buildSetRBG(1, true, map[string]string{
+					v1alpha1.DrainingAnnotationKey: now.Add(-30 * time.Second).Format(time.RFC3339),
+				}),
+			},
+			expectDraining: []string{"test-rbgset-1"},
+			expectCreated:  []string{"test-rbgset-2"},
+			expectRequeue:  true,
+			expectReplicas: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbgset := &v1alpha1.RoleBasedGroupSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+				Spec: v1alpha1.RoleBasedGroupSetSpec{
+					Replicas:      ptr.To(int32(2)),
+					ScaleStrategy: &v1alpha1.RoleBasedGroupSetScaleStrategy{Drain: tt.drain},
+				},
+			}
+			objs := []runtime.Object{rbgset}
+			for _, rbg := range tt.rbgs {
+				objs = append(objs, rbg)
+				objs = append(objs, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      rbg.Name + "-pod",
+						Namespace: "default",
+						Labels:    map[string]string{v1alpha1.SetNameLabelKey: rbg.Name, "serving": "true"},
+					},
+				})
+			}
+			r := &RoleBasedGroupSetReconciler{
+				client: fake.NewClientBuilder().WithScheme(scheme).
+					WithRuntimeObjects(objs...).
+					WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+				scheme:   scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{Namespace: rbgset.Namespace, Name: rbgset.Name},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectRequeue, result.RequeueAfter > 0)
+
+			for _, name := range tt.expectDeleted {
+				err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, &v1alpha1.RoleBasedGroup{})
+				assert.True(t, apierrors.IsNotFound(err), "rbg %s should be deleted", name)
+			}
+			for _, name := range tt.expectDraining {
+				rbg := &v1alpha1.RoleBasedGroup{}
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, rbg))
+				assert.Contains(t, rbg.Annotations, v1alpha1.DrainingAnnotationKey)
+
+				pod := &corev1.Pod{}
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: name + "-pod", Namespace: "default"}, pod))
+				assert.NotContains(t, pod.Labels, "serving", "routing label of pod %s should be removed", pod.Name)
+			}
+			for _, name := range tt.expectCreated {
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, &v1alpha1.RoleBasedGroup{}))
+			}
+			if tt.expectPodLabeled {
+				pod := &corev1.Pod{}
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset-0-pod", Namespace: "default"}, pod))
+				assert.Contains(t, pod.Labels, "serving")
+			}
+
+			updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: rbgset.Name, Namespace: rbgset.Namespace}, updatedRBGSet))
+			assert.Equal(t, tt.expectReplicas, updatedRBGSet.Status.Replicas)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// rbgSetRevision returns the revision of rbgset, i.e. the hash of the fields which decide the specs and the
+// PodGroup of its rbgs.
+func rbgSetRevision(rbgset *workloadsv1alpha1.RoleBasedGroupSet) string {
+	return hashObject(struct {
+		Template       workloadsv1alpha1.RoleBasedGroupSpec               `json:"template"`
+		Overrides      []workloadsv1alpha1.RoleBasedGroupSetOverride      `json:"overrides,omitempty"`
+		TopologySpread *workloadsv1alpha1.RoleBasedGroupSetTopologySpread `json:"topologySpread,omitempty"`
+		PodGroupPolicy *workloadsv1alpha1.PodGroupPolicy                  `json:"podGroupPolicy,omitempty"`
+	}{rbgset.Spec.Template, rbgset.Spec.Overrides, rbgset.Spec.TopologySpread, rbgset.Spec.PodGroupPolicy})
+}
+
+// syncRevisionLabels updates the revision and spec hash labels of rbg to the ones of desired.
+func (r *RoleBasedGroupSetReconciler) syncRevisionLabels(
+	ctx context.Context, rbg, desired *workloadsv1alpha1.RoleBasedGroup,
+) error {
+	revision := desired.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey]
+	specHash := desired.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey]
+	if rbg.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey] == revision &&
+		rbg.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey] == specHash {
+		return nil
+	}
+	patch := client.MergeFrom(rbg.DeepCopy())
+	if rbg.Labels == nil {
+		rbg.Labels = map[string]string{}
+	}
+	rbg.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey] = revision
+	rbg.Labels[workloadsv1alpha1.SetRBGSpecHashLabelKey] = specHash
+	if err := r.client.Patch(ctx, rbg, patch); err != nil {
+		return fmt.Errorf("failed to update revision of RoleBasedGroup %s: %w", rbg.Name, err)
+	}
+	return nil
+}
+
+// revisionStatuses counts the rbgs on each revision, the rbgs created before the revision label was introduced
+// are not counted.
+func revisionStatuses(rbgs []*workloadsv1alpha1.RoleBasedGroup) []workloadsv1alpha1.RoleBasedGroupSetRevisionStatus {
+	counts := make(map[string]int32)
+	for _, rbg := range rbgs {
+		if revision := rbg.Labels[workloadsv1alpha1.SetRBGRevisionLabelKey]; revision != "" {
+			counts[revision]++
+		}
+	}
+	var statuses []workloadsv1alpha1.RoleBasedGroupSetRevisionStatus
+	for revision, replicas := range counts {
+		statuses = append(statuses, workloadsv1alpha1.RoleBasedGroupSetRevisionStatus{
+			Revision: revision,
+			Replicas: replicas,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b workloadsv1alpha1.RoleBasedGroupSetRevisionStatus) int {
+		return strings.Compare(a.Revision, b.Revision)
+	})
+	return statuses
+}
+
+// aggregateRoleStatuses sums up the role statuses of the rbgs by role. The roles are ordered as in the template
+// of rbgset, followed by the roles which are only defined by overrides.
+func aggregateRoleStatuses(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbgs []*workloadsv1alpha1.RoleBasedGroup,
+) []workloadsv1alpha1.RoleBasedGroupSetRoleStatus {
+	statuses := make(map[string]*workloadsv1alpha1.RoleBasedGroupSetRoleStatus)
+	for _, rbg := range rbgs {
+		for _, roleStatus := range rbg.Status.RoleStatuses {
+			status, ok := statuses[roleStatus.Name]
+			if !ok {
+				status = &workloadsv1alpha1.RoleBasedGroupSetRoleStatus{Name: roleStatus.Name}
+				statuses[roleStatus.Name] = status
+			}
+			status.Replicas += roleStatus.Replicas
+			status.ReadyReplicas += roleStatus.ReadyReplicas
+			status.UpdatedReplicas += roleStatus.UpdatedReplicas
+		}
+	}
+
+	order := make(map[string]int, len(rbgset.Spec.Template.Roles))
+	for i, role := range rbgset.Spec.Template.Roles {
+		order[role.Name] = i
+	}
+	rank := func(name string) int {
+		if i, ok := order[name]; ok {
+			return i
+		}
+		return len(order)
+	}
+	var result []workloadsv1alpha1.RoleBasedGroupSetRoleStatus
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	slices.SortFunc(result, func(a, b workloadsv1alpha1.RoleBasedGroupSetRoleStatus) int {
+		if c := rank(a.Name) - rank(b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// setProgressingConditions sets the Progressing and RollingUpdateInProgress conditions of status.
+func setProgressingConditions(status *workloadsv1alpha1.RoleBasedGroupSetStatus, desiredReplicas int32, drainingReplicas int) {
+	rollingUpdate := metav1.Condition{
+		Type:    string(workloadsv1alpha1.RoleBasedGroupSetRollingUpdateInProgress),
+		Status:  metav1.ConditionFalse,
+		Reason:  "AllReplicasUpdated",
+		Message: "All RoleBasedGroup replicas are updated.",
+	}
+	if status.UpdatedReplicas < status.Replicas {
+		rollingUpdate.Status = metav1.ConditionTrue
+		rollingUpdate.Reason = "RollingUpdate"
+		rollingUpdate.Message = fmt.Sprintf("Updating RoleBasedGroups to revision %s (%d/%d)",
+			status.UpdateRevision, status.UpdatedReplicas, status.Replicas)
+	}
+	meta.SetStatusCondition(&status.Conditions, rollingUpdate)
+
+	progressing := metav1.Condition{
+		Type:   string(workloadsv1alpha1.RoleBasedGroupSetProgressing),
+		Status: metav1.ConditionTrue,
+	}
+	switch {
+	case status.Replicas != desiredReplicas || drainingReplicas > 0:
+		progressing.Reason = "ScalingReplicas"
+		progressing.Message = fmt.Sprintf("Scaling RoleBasedGroups to %d replicas (%d existing, %d draining)",
+			desiredReplicas, status.Replicas, drainingReplicas)
+	case rollingUpdate.Status == metav1.ConditionTrue:
+		progressing.Reason = rollingUpdate.Reason
+		progressing.Message = rollingUpdate.Message
+	case status.ReadyReplicas < desiredReplicas:
+		progressing.Reason = "ReplicasNotReady"
+		progressing.Message = fmt.Sprintf("Waiting for replicas to be ready (%d/%d)", status.ReadyReplicas, desiredReplicas)
+	default:
+		progressing.Status = metav1.ConditionFalse
+		progressing.Reason = "ReplicasStable"
+		progressing.Message = "All RoleBasedGroup replicas are updated and ready."
+	}
+	meta.SetStatusCondition(&status.Conditions, progressing)
+}
@@ -0,0 +1,183 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestAggregateRoleStatuses(t *testing.T) {
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			Template: v1alpha1.RoleBasedGroupSpec{
+				Roles: []v1alpha1.RoleSpec{{Name: "prefill"}, {Name: "decode"}},
+			},
+		},
+	}
+	rbg0 := buildSetRBG(0, true, nil)
+	rbg0.Status.RoleStatuses = []v1alpha1.RoleStatus{
+		{Name: "decode", Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2},
+		{Name: "prefill", Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1},
+	}
+	rbg1 := buildSetRBG(1, false, nil)
+	rbg1.Status.RoleStatuses = []v1alpha1.RoleStatus{
+		{Name: "prefill", Replicas: 1, ReadyReplicas: 0, UpdatedReplicas: 1},
+		{Name: "decode", Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 0},
+		{Name: "router", Replicas: 1, ReadyReplicas: 1},
+	}
+
+	expected := []v1alpha1.RoleBasedGroupSetRoleStatus{
+		{Name: "prefill", Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 2},
+		{Name: "decode", Replicas: 4, ReadyReplicas: 3, UpdatedReplicas: 2},
+		{Name: "router", Replicas: 1, ReadyReplicas: 1},
+	}
+	assert.Equal(t, expected, aggregateRoleStatuses(rbgset, []*v1alpha1.RoleBasedGroup{rbg0, rbg1}))
+}
+
+func TestRevisionStatuses(t *testing.T) {
+	rbgs := []*v1alpha1.RoleBasedGroup{
+		buildSetRBG(0, true, nil),
+		buildSetRBG(1, true, nil),
+		buildSetRBG(2, true, nil),
+		buildSetRBG(3, true, nil),
+	}
+	rbgs[0].Labels[v1alpha1.SetRBGRevisionLabelKey] = "rev-b"
+	rbgs[1].Labels[v1alpha1.SetRBGRevisionLabelKey] = "rev-a"
+	rbgs[2].Labels[v1alpha1.SetRBGRevisionLabelKey] = "rev-b"
+
+	expected := []v1alpha1.RoleBasedGroupSetRevisionStatus{
+		{Revision: "rev-a", Replicas: 1},
+		{Revision: "rev-b", Replicas: 2},
+	}
+	assert.Equal(t, expected, revisionStatuses(rbgs))
+}
+
+func TestSetProgressingConditions(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            v1alpha1.RoleBasedGroupSetStatus
+		draining          int
+		expectProgressing string
+		expectRolling     metav1.ConditionStatus
+	}{
+		{
+			name:              "Scaling",
+			status:            v1alpha1.RoleBasedGroupSetStatus{Replicas: 1, ReadyReplicas: 1, UpdatedReplicas: 1},
+			expectProgressing: "ScalingReplicas",
+			expectRolling:     metav1.ConditionFalse,
+		},
+		{
+			name:              "Draining",
+			status:            v1alpha1.RoleBasedGroupSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2},
+			draining:          1,
+			expectProgressing: "ScalingReplicas",
+			expectRolling:     metav1.ConditionFalse,
+		},
+		{
+			name:              "Rolling update",
+			status:            v1alpha1.RoleBasedGroupSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 1},
+			expectProgressing: "RollingUpdate",
+			expectRolling:     metav1.ConditionTrue,
+		},
+		{
+			name:              "Not ready",
+			status:            v1alpha1.RoleBasedGroupSetStatus{Replicas: 2, ReadyReplicas: 1, UpdatedReplicas: 2},
+			expectProgressing: "ReplicasNotReady",
+			expectRolling:     metav1.ConditionFalse,
+		},
+		{
+			name:              "Stable",
+			status:            v1alpha1.RoleBasedGroupSetStatus{Replicas: 2, ReadyReplicas: 2, UpdatedReplicas: 2},
+			expectProgressing: "ReplicasStable",
+			expectRolling:     metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setProgressingConditions(&tt.status, 2, tt.draining)
+
+			progressing := meta.FindStatusCondition(tt.status.Conditions, string(v1alpha1.RoleBasedGroupSetProgressing))
+			assert.NotNil(t, progressing)
+			assert.Equal(t, tt.expectProgressing, progressing.Reason)
+			assert.Equal(t, tt.expectProgressing != "ReplicasStable", progressing.Status == metav1.ConditionTrue)
+
+			rolling := meta.FindStatusCondition(tt.status.Conditions, string(v1alpha1.RoleBasedGroupSetRollingUpdateInProgress))
+			assert.NotNil(t, rolling)
+			assert.Equal(t, tt.expectRolling, rolling.Status)
+		})
+	}
+}
+
+func TestRoleBasedGroupSetReconciler_Reconcile_Revision(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset", Namespace: "default", UID: "test-uid"},
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			Replicas: ptr.To(int32(2)),
+			Template: v1alpha1.RoleBasedGroupSpec{
+				Roles: []v1alpha1.RoleSpec{{Name: "prefill", Replicas: ptr.To(int32(1))}},
+			},
+			RolloutStrategy: &v1alpha1.RoleBasedGroupSetRolloutStrategy{
+				RollingUpdate: &v1alpha1.RoleBasedGroupSetRollingUpdate{Partition: ptr.To(int32(1))},
+			},
+		},
+	}
+	revision := rbgSetRevision(rbgset)
+
+	// rbg 0 is up to date but was created before the revision label, rbg 1 is outdated.
+	rbg0, err := newRBGForSet(rbgset, 0)
+	assert.NoError(t, err)
+	delete(rbg0.Labels, v1alpha1.SetRBGRevisionLabelKey)
+	rbg0.Status.Conditions = []metav1.Condition{{Type: string(v1alpha1.RoleBasedGroupReady), Status: metav1.ConditionTrue}}
+	rbg1, err := newRBGForSet(rbgset, 1)
+	assert.NoError(t, err)
+	rbg1.Labels[v1alpha1.SetRBGRevisionLabelKey] = "old"
+	rbg1.Labels[v1alpha1.SetRBGSpecHashLabelKey] = "old"
+	rbg1.Spec.Roles[0].Replicas = ptr.To(int32(2))
+
+	r := &RoleBasedGroupSetReconciler{
+		client: fake.NewClientBuilder().WithScheme(scheme).
+			WithRuntimeObjects(rbgset, rbg0, rbg1).
+			WithStatusSubresource(&v1alpha1.RoleBasedGroupSet{}).Build(),
+		scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+	}
+	_, err = r.Reconcile(context.TODO(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: rbgset.Namespace, Name: rbgset.Name},
+	})
+	assert.NoError(t, err)
+
+	updatedRBG0 := &v1alpha1.RoleBasedGroup{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset-0", Namespace: "default"}, updatedRBG0))
+	assert.Equal(t, revision, updatedRBG0.Labels[v1alpha1.SetRBGRevisionLabelKey])
+
+	updatedRBG1 := &v1alpha1.RoleBasedGroup{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: "test-rbgset-1", Namespace: "default"}, updatedRBG1))
+	assert.Equal(t, revision, updatedRBG1.Labels[v1alpha1.SetRBGRevisionLabelKey])
+
+	updatedRBGSet := &v1alpha1.RoleBasedGroupSet{}
+	assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Name: rbgset.Name, Namespace: rbgset.Namespace}, updatedRBGSet))
+	status := updatedRBGSet.Status
+	assert.Equal(t, revision, status.UpdateRevision)
+	assert.Equal(t, []v1alpha1.RoleBasedGroupSetRevisionStatus{{Revision: revision, Replicas: 2}}, status.Revisions)
+	assert.Len(t, status.IndexStatuses, 2)
+	assert.True(t, status.IndexStatuses[0].Ready)
+	assert.Equal(t, revision, status.IndexStatuses[0].Revision)
+	assert.False(t, status.IndexStatuses[1].Ready)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, string(v1alpha1.RoleBasedGroupSetProgressing)))
+}
@@ -0,0 +1,74 @@
+package workloads
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// topologyValueForIndex returns the topology domain the rbg of index is pinned to.
+func topologyValueForIndex(spread *workloadsv1alpha1.RoleBasedGroupSetTopologySpread, index int) string {
+	return spread.Values[index%len(spread.Values)]
+}
+
+// injectTopologyAffinity requires the pods of template to run in the topology domain value of key. The requirement
+// is added to every required node selector term, since the terms are ORed.
+func injectTopologyAffinity(template *corev1.PodTemplateSpec, key, value string) {
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      key,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{value},
+	}
+
+	if template.Spec.Affinity == nil {
+		template.Spec.Affinity = &corev1.Affinity{}
+	}
+	if template.Spec.Affinity.NodeAffinity == nil {
+		template.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := template.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(required.NodeSelectorTerms) == 0 {
+		required.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range required.NodeSelectorTerms {
+		term := &required.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+}
+
+// topologyDistribution counts the rbgs of rbgset pinned to each topology domain, in the order of the domains.
+func topologyDistribution(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbgs []*workloadsv1alpha1.RoleBasedGroup,
+) []workloadsv1alpha1.TopologyDomainStatus {
+	spread := rbgset.Spec.TopologySpread
+	if spread == nil || len(spread.Values) == 0 {
+		return nil
+	}
+
+	// a domain may be listed more than once to weight it
+	distribution := []workloadsv1alpha1.TopologyDomainStatus{}
+	positions := make(map[string]int)
+	for _, value := range spread.Values {
+		if _, ok := positions[value]; !ok {
+			positions[value] = len(distribution)
+			distribution = append(distribution, workloadsv1alpha1.TopologyDomainStatus{Value: value})
+		}
+	}
+
+	for _, rbg := range rbgs {
+		index := rbgIndex(rbg)
+		if index < 0 {
+			continue
+		}
+		domain := &distribution[positions[topologyValueForIndex(spread, index)]]
+		domain.Replicas++
+		if meta.IsStatusConditionTrue(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupReady)) {
+			domain.ReadyReplicas++
+		}
+	}
+	return distribution
+}
@@ -0,0 +1,109 @@
+package workloads
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestInjectTopologyAffinity(t *testing.T) {
+	zoneA := corev1.NodeSelectorRequirement{
+		Key:      "topology.kubernetes.io/zone",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{"zone-a"},
+	}
+	gpu := corev1.NodeSelectorRequirement{Key: "gpu", Operator: corev1.NodeSelectorOpExists}
+	cpu := corev1.NodeSelectorRequirement{Key: "cpu", Operator: corev1.NodeSelectorOpExists}
+
+	tests := []struct {
+		name     string
+		affinity *corev1.Affinity
+		expected []corev1.NodeSelectorTerm
+	}{
+		{
+			name:     "No affinity",
+			expected: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zoneA}}},
+		},
+		{
+			name: "Requirement is added to every term",
+			affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{MatchExpressions: []corev1.NodeSelectorRequirement{gpu}},
+							{MatchExpressions: []corev1.NodeSelectorRequirement{cpu}},
+						},
+					},
+				},
+			},
+			expected: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{gpu, zoneA}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{cpu, zoneA}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Affinity: tt.affinity}}
+			injectTopologyAffinity(template, "topology.kubernetes.io/zone", "zone-a")
+			assert.Equal(t, tt.expected,
+				template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+		})
+	}
+}
+
+func TestRBGSpecForIndex_TopologySpread(t *testing.T) {
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			Template: v1alpha1.RoleBasedGroupSpec{
+				Roles: []v1alpha1.RoleSpec{
+					{Name: "prefill", Replicas: ptr.To(int32(1))},
+					{Name: "decode", Replicas: ptr.To(int32(1))},
+				},
+			},
+			TopologySpread: &v1alpha1.RoleBasedGroupSetTopologySpread{
+				TopologyKey: "topology.kubernetes.io/zone",
+				Values:      []string{"zone-a", "zone-b"},
+			},
+		},
+	}
+
+	for index, zone := range []string{"zone-a", "zone-b", "zone-a"} {
+		spec, err := rbgSpecForIndex(rbgset, index)
+		assert.NoError(t, err)
+		for _, role := range spec.Roles {
+			terms := role.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			assert.Equal(t, []string{zone}, terms[0].MatchExpressions[0].Values, "role %s of index %d", role.Name, index)
+		}
+	}
+	// the template of the set is not modified
+	assert.Nil(t, rbgset.Spec.Template.Roles[0].Template.Spec.Affinity)
+}
+
+func TestTopologyDistribution(t *testing.T) {
+	rbgset := &v1alpha1.RoleBasedGroupSet{
+		Spec: v1alpha1.RoleBasedGroupSetSpec{
+			TopologySpread: &v1alpha1.RoleBasedGroupSetTopologySpread{
+				TopologyKey: "topology.kubernetes.io/zone",
+				Values:      []string{"zone-a", "zone-b", "zone-a"},
+			},
+		},
+	}
+	rbgs := []*v1alpha1.RoleBasedGroup{
+		buildSetRBG(0, true, nil),
+		buildSetRBG(1, true, nil),
+		buildSetRBG(2, false, nil),
+		buildSetRBG(4, true, nil),
+	}
+
+	assert.Equal(t, []v1alpha1.TopologyDomainStatus{
+		{Value: "zone-a", Replicas: 2, ReadyReplicas: 1},
+		{Value: "zone-b", Replicas: 2, ReadyReplicas: 2},
+	}, topologyDistribution(rbgset, rbgs))
+
+	assert.Nil(t, topologyDistribution(&v1alpha1.RoleBasedGroupSet{}, rbgs))
+}
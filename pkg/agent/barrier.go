@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// Barrier waits until roles of a RoleBasedGroup are ready, as recorded by the controller in the status of the
+// RoleBasedGroup: all replicas of a role are ready, which is also how the controller checks the dependencies of a
+// role. A role given as <role>=<threshold> is ready once the threshold of its replicas is ready instead, a number or
+// a percentage. The service account of the pod needs to get RoleBasedGroups.
+type Barrier struct {
+	Client    client.Client
+	Namespace string
+	Group     string
+	Roles     []string
+	Interval  time.Duration
+}
+
+// Wait blocks until the roles are ready, or returns the error of ctx if ctx is done first.
+func (b *Barrier) Wait(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithValues("group", b.Group)
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+	for {
+		rbg := &workloadsv1alpha1.RoleBasedGroup{}
+		if err := b.Client.Get(ctx, types.NamespacedName{Name: b.Group, Namespace: b.Namespace}, rbg); err != nil {
+			logger.Error(err, "Failed to get the RoleBasedGroup")
+		} else if notReady, err := notReadyRoles(rbg, b.Roles); err != nil {
+			return err
+		} else if len(notReady) == 0 {
+			logger.Info("Roles are ready", "roles", b.Roles)
+			return nil
+		} else {
+			logger.Info("Waiting for roles to be ready", "roles", notReady)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func notReadyRoles(rbg *workloadsv1alpha1.RoleBasedGroup, roles []string) ([]string, error) {
+	var notReady []string
+	for _, role := range roles {
+		name, threshold, found := strings.Cut(role, "=")
+		status, ok := rbg.GetRoleStatus(name)
+		if !ok {
+			notReady = append(notReady, role)
+			continue
+		}
+		want := status.Replicas
+		if found {
+			value := intstr.Parse(threshold)
+			scaled, err := intstr.GetScaledValueFromIntOrPercent(&value, int(status.Replicas), true)
+			if err != nil {
+				return nil, fmt.Errorf("invalid threshold of role %s: %w", name, err)
+			}
+			want = min(int32(scaled), status.Replicas)
+		}
+		if status.ReadyReplicas < want {
+			notReady = append(notReady, role)
+		}
+	}
+	return notReady, nil
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestBarrier(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+		Status: workloadsv1alpha1.RoleBasedGroupStatus{
+			RoleStatuses: []workloadsv1alpha1.RoleStatus{
+				{Name: "prefill", Replicas: 2, ReadyReplicas: 2},
+				{Name: "decode", Replicas: 2, ReadyReplicas: 1},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rbg).WithStatusSubresource(rbg).Build()
+	newBarrier := func(roles ...string) *Barrier {
+		return &Barrier{Client: c, Namespace: "default", Group: "test-rbg", Roles: roles, Interval: 10 * time.Millisecond}
+	}
+
+	require.NoError(t, newBarrier("prefill").Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, newBarrier("prefill", "decode").Wait(ctx), context.DeadlineExceeded)
+
+	// a threshold of the replicas is enough
+	require.NoError(t, newBarrier("prefill", "decode=50%").Wait(context.Background()))
+	require.NoError(t, newBarrier("decode=1").Wait(context.Background()))
+	assert.ErrorContains(t, newBarrier("decode=half%").Wait(context.Background()), "invalid threshold")
+
+	// the roles not in the status are not ready
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, newBarrier("router").Wait(ctx), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() {
+		done <- newBarrier("prefill", "decode").Wait(context.Background())
+	}()
+	rbg.Status.RoleStatuses[1].ReadyReplicas = 2
+	require.NoError(t, c.Status().Update(context.Background(), rbg))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("barrier is not released once the roles are ready")
+	}
+}
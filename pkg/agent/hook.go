@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Hook is run when the discovery config changes, e.g. to reload the engine.
+type Hook interface {
+	Name() string
+	Run(ctx context.Context, snapshot Snapshot) error
+}
+
+// ExecHook runs a shell command, with the revision and the config in the env vars RBG_CONFIG_REVISION and
+// RBG_CONFIG. Sending a signal to the engine, e.g. `pkill -HUP sglang`, requires shareProcessNamespace of the pod.
+type ExecHook struct {
+	Command string
+}
+
+var _ Hook = &ExecHook{}
+
+func (h *ExecHook) Name() string {
+	return "exec"
+}
+
+func (h *ExecHook) Run(ctx context.Context, snapshot Snapshot) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(),
+		"RBG_CONFIG_REVISION="+strconv.FormatInt(snapshot.Revision, 10),
+		"RBG_CONFIG="+string(snapshot.Data),
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("command failed: %w, output: %s", err, output)
+	}
+	return nil
+}
+
+// HTTPHook posts the config to an admin API of the engine.
+type HTTPHook struct {
+	URL    string
+	Client *http.Client
+}
+
+var _ Hook = &HTTPHook{}
+
+func (h *HTTPHook) Name() string {
+	return "http"
+}
+
+func (h *HTTPHook) Run(ctx context.Context, snapshot Snapshot) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(snapshot.Data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(RevisionHeader, strconv.FormatInt(snapshot.Revision, 10))
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// HookRunner runs the hooks on every change of the discovery config. The changes happening while the hooks run
+// are coalesced, so the hooks always see the latest config.
+type HookRunner struct {
+	Hooks   []Hook
+	Timeout time.Duration
+	// RunOnStart also runs the hooks for the config loaded at start, which the engine may have read already.
+	RunOnStart bool
+}
+
+func (r *HookRunner) Run(ctx context.Context, store *Store) {
+	logger := log.FromContext(ctx)
+
+	// the first revision is the config loaded at start
+	var revision int64
+	if !r.RunOnStart {
+		revision = 1
+	}
+	for {
+		snapshot, ok := store.Wait(ctx, revision)
+		if !ok {
+			return
+		}
+		revision = snapshot.Revision
+		for _, hook := range r.Hooks {
+			hookCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+			if err := hook.Run(hookCtx, snapshot); err != nil {
+				logger.Error(err, "Failed to run hook", "hook", hook.Name(), "revision", snapshot.Revision)
+			} else {
+				logger.Info("Hook succeeded", "hook", hook.Name(), "revision", snapshot.Revision)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookRunner(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r.Header.Get(RevisionHeader)+":"+string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+	output := filepath.Join(t.TempDir(), "revision")
+
+	store := NewStore()
+	store.Update([]byte("a"))
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	runner := &HookRunner{
+		Hooks: []Hook{
+			&HTTPHook{URL: server.URL, Client: http.DefaultClient},
+			&ExecHook{Command: "echo -n $RBG_CONFIG_REVISION $RBG_CONFIG > " + output},
+		},
+		Timeout: time.Second,
+	}
+	go runner.Run(ctx, store)
+
+	// the config loaded at start does not run the hooks
+	store.Update([]byte("b"))
+
+	assert.Eventually(t, func() bool {
+		data, _ := os.ReadFile(output)
+		return string(data) == "2 b"
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"2:b"}, received)
+}
+
+func TestHTTPHook_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook := &HTTPHook{URL: server.URL, Client: http.DefaultClient}
+	assert.Error(t, hook.Run(context.TODO(), Snapshot{Revision: 1, Data: []byte("a")}))
+}
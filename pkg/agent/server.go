@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/rbgs/pkg/discovery"
+)
+
+const (
+	// RevisionHeader carries the revision of the config served by the agent.
+	RevisionHeader = "X-Rbg-Revision"
+
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+// Server serves the discovery config held by a Store to the containers of the pod:
+//
+//	GET /v1/config        the discovery config as mounted
+//	GET /v1/group         the group of the config in JSON
+//	GET /v1/roles/{role}  the size and instances of a role in JSON
+//	GET /healthz          200 once the config is loaded
+//
+// The /v1 endpoints long-poll with ?revision=N: the request blocks until the revision of the config is newer than
+// N, or responds 304 after ?timeout (default 30s). The revision is returned in the X-Rbg-Revision header, so a
+// client watches the membership by passing the last revision it received.
+type Server struct {
+	store *Store
+	mux   *http.ServeMux
+}
+
+func NewServer(store *Store) *Server {
+	s := &Server{store: store, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /v1/config", s.serveConfig)
+	s.mux.HandleFunc("GET /v1/group", s.serveGroup)
+	s.mux.HandleFunc("GET /v1/roles/{role}", s.serveRole)
+	s.mux.HandleFunc("GET /healthz", s.serveHealthz)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) serveConfig(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := s.wait(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set(RevisionHeader, strconv.FormatInt(snapshot.Revision, 10))
+	_, _ = w.Write(snapshot.Data)
+}
+
+func (s *Server) serveGroup(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := s.wait(w, r)
+	if !ok {
+		return
+	}
+	config, ok := parseConfig(w, snapshot)
+	if !ok {
+		return
+	}
+	writeJSON(w, snapshot, config.Group)
+}
+
+func (s *Server) serveRole(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := s.wait(w, r)
+	if !ok {
+		return
+	}
+	config, ok := parseConfig(w, snapshot)
+	if !ok {
+		return
+	}
+	role, found := config.Roles[r.PathValue("role")]
+	if !found {
+		http.Error(w, "role not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, snapshot, role)
+}
+
+func (s *Server) serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	if snapshot, _ := s.store.Get(); snapshot.Revision == 0 {
+		http.Error(w, "discovery config not loaded", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// wait returns the snapshot to serve to r, waiting for a newer revision if r long-polls. It writes the response and
+// returns false if there is nothing to serve.
+func (s *Server) wait(w http.ResponseWriter, r *http.Request) (Snapshot, bool) {
+	query := r.URL.Query()
+	if query.Get("revision") == "" {
+		snapshot, _ := s.store.Get()
+		if snapshot.Revision == 0 {
+			http.Error(w, "discovery config not loaded", http.StatusServiceUnavailable)
+			return snapshot, false
+		}
+		return snapshot, true
+	}
+
+	revision, err := strconv.ParseInt(query.Get("revision"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return Snapshot{}, false
+	}
+	timeout := defaultWaitTimeout
+	if query.Get("timeout") != "" {
+		if timeout, err = time.ParseDuration(query.Get("timeout")); err != nil || timeout <= 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return Snapshot{}, false
+		}
+		timeout = min(timeout, maxWaitTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	snapshot, changed := s.store.Wait(ctx, revision)
+	if !changed {
+		w.Header().Set(RevisionHeader, strconv.FormatInt(snapshot.Revision, 10))
+		w.WriteHeader(http.StatusNotModified)
+		return snapshot, false
+	}
+	return snapshot, true
+}
+
+// parseConfig parses the config in YAML or JSON, the other formats are only served by /v1/config.
+func parseConfig(w http.ResponseWriter, snapshot Snapshot) (*discovery.ClusterConfig, bool) {
+	config := &discovery.ClusterConfig{}
+	if err := yaml.Unmarshal(snapshot.Data, config); err != nil {
+		http.Error(w, "discovery config is not in YAML or JSON format", http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	return config, true
+}
+
+func writeJSON(w http.ResponseWriter, snapshot Snapshot, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(RevisionHeader, strconv.FormatInt(snapshot.Revision, 10))
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `version: v1
+group:
+  name: test-rbg
+  size: 2
+  roles:
+  - prefill
+  - decode
+roles:
+  prefill:
+    size: 1
+    instances:
+    - address: prefill-0.test-rbg-prefill
+      ports:
+        http: 8000
+  decode:
+    size: 0
+    instances: []
+`
+
+func TestServer(t *testing.T) {
+	store := NewStore()
+	server := httptest.NewServer(NewServer(store))
+	defer server.Close()
+
+	get := func(path string) (int, string, string) {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get(RevisionHeader), string(body)
+	}
+
+	status, _, _ := get("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	status, _, _ = get("/v1/config")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	store.Update([]byte(testConfig))
+	status, _, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, revision, body := get("/v1/config")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "1", revision)
+	assert.Equal(t, testConfig, body)
+
+	status, _, body = get("/v1/group")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"name":"test-rbg","size":2,"roles":["prefill","decode"]}`, body)
+
+	status, _, body = get("/v1/roles/prefill")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"size":1,"instances":[{"address":"prefill-0.test-rbg-prefill","ports":{"http":8000}}]}`, body)
+
+	status, _, _ = get("/v1/roles/router")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// long-poll times out without a change
+	status, revision, _ = get("/v1/config?revision=1&timeout=10ms")
+	assert.Equal(t, http.StatusNotModified, status)
+	assert.Equal(t, "1", revision)
+
+	// long-poll returns on the next change
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		store.Update([]byte("version: v1\n"))
+	}()
+	status, revision, body = get("/v1/config?revision=1&timeout=5s")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "2", revision)
+	assert.Equal(t, "version: v1\n", body)
+
+	status, _, _ = get("/v1/config?revision=x")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// the other formats are only served as is
+	store.Update([]byte("GROUP_NAME=test-rbg\n"))
+	status, _, _ = get("/v1/group")
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Source feeds the discovery config into a Store until ctx is done.
+type Source interface {
+	Run(ctx context.Context, store *Store) error
+}
+
+// FileSource polls the discovery config mounted from the ConfigMap. The kubelet replaces the file through a symlink
+// when the ConfigMap changes, so the file is read periodically rather than watched.
+type FileSource struct {
+	Path     string
+	Interval time.Duration
+}
+
+var _ Source = &FileSource{}
+
+func (s *FileSource) Run(ctx context.Context, store *Store) error {
+	logger := log.FromContext(ctx).WithValues("path", s.Path)
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		data, err := os.ReadFile(s.Path)
+		if err != nil {
+			logger.Error(err, "Failed to read the discovery config")
+		} else if store.Update(data) {
+			logger.Info("Discovery config changed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ConfigMapSource watches the discovery ConfigMap through the API server, which observes the changes faster than
+// the kubelet refreshes the mounted file. The service account of the pod needs to get, list and watch ConfigMaps.
+type ConfigMapSource struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	Key       string
+}
+
+var _ Source = &ConfigMapSource{}
+
+func (s *ConfigMapSource) Run(ctx context.Context, store *Store) error {
+	logger := log.FromContext(ctx).WithValues("configmap", s.Name, "key", s.Key)
+	update := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		data, ok := cm.Data[s.Key]
+		if !ok {
+			logger.Info("Key not found in the discovery ConfigMap")
+			return
+		}
+		if store.Update([]byte(data)) {
+			logger.Info("Discovery config changed")
+		}
+	}
+
+	lw := cache.NewListWatchFromClient(s.Client.CoreV1().RESTClient(), "configmaps", s.Namespace,
+		fields.OneTermEqualSelector("metadata.name", s.Name))
+	informer := cache.NewSharedInformer(lw, &corev1.ConfigMap{}, 0)
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(_, obj interface{}) { update(obj) },
+	}); err != nil {
+		return err
+	}
+	informer.Run(ctx.Done())
+	return nil
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// Snapshot is a revision of the discovery config. Revision 0 means the config is not loaded yet.
+type Snapshot struct {
+	Revision int64
+	Data     []byte
+}
+
+// Store holds the latest discovery config observed by the agent and notifies the waiters when it changes.
+type Store struct {
+	mu       sync.RWMutex
+	snapshot Snapshot
+	// changed is closed and replaced on every change
+	changed chan struct{}
+}
+
+func NewStore() *Store {
+	return &Store{changed: make(chan struct{})}
+}
+
+// Update stores data as a new revision if it differs from the current one, and reports whether it is changed.
+func (s *Store) Update(data []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snapshot.Revision > 0 && bytes.Equal(s.snapshot.Data, data) {
+		return false
+	}
+	s.snapshot = Snapshot{Revision: s.snapshot.Revision + 1, Data: data}
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return true
+}
+
+// Get returns the current snapshot and a channel closed on the next change.
+func (s *Store) Get() (Snapshot, <-chan struct{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshot, s.changed
+}
+
+// Wait blocks until the revision of the config is newer than revision or ctx is done. It returns the current
+// snapshot and whether it is newer than revision.
+func (s *Store) Wait(ctx context.Context, revision int64) (Snapshot, bool) {
+	for {
+		snapshot, changed := s.Get()
+		if snapshot.Revision > revision {
+			return snapshot, true
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return snapshot, false
+		}
+	}
+}
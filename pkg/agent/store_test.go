@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore()
+	snapshot, _ := store.Get()
+	assert.Equal(t, int64(0), snapshot.Revision)
+
+	assert.True(t, store.Update([]byte("a")))
+	assert.False(t, store.Update([]byte("a")))
+	snapshot, _ = store.Get()
+	assert.Equal(t, Snapshot{Revision: 1, Data: []byte("a")}, snapshot)
+
+	// the waiter is woken up by the next change
+	done := make(chan Snapshot)
+	go func() {
+		snapshot, _ := store.Wait(context.TODO(), 1)
+		done <- snapshot
+	}()
+	store.Update([]byte("b"))
+	select {
+	case snapshot = <-done:
+		assert.Equal(t, Snapshot{Revision: 2, Data: []byte("b")}, snapshot)
+	case <-time.After(time.Second):
+		t.Fatal("waiter is not woken up")
+	}
+
+	// an outdated revision returns at once
+	snapshot, changed := store.Wait(context.TODO(), 1)
+	assert.True(t, changed)
+	assert.Equal(t, int64(2), snapshot.Revision)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	_, changed = store.Wait(ctx, 2)
+	assert.False(t, changed)
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("version: v1\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	store := NewStore()
+	go func() {
+		_ = (&FileSource{Path: path, Interval: 10 * time.Millisecond}).Run(ctx, store)
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, time.Second)
+	defer waitCancel()
+	snapshot, changed := store.Wait(waitCtx, 0)
+	assert.True(t, changed)
+	assert.Equal(t, "version: v1\n", string(snapshot.Data))
+
+	assert.NoError(t, os.WriteFile(path, []byte("version: v2\n"), 0o644))
+	snapshot, changed = store.Wait(waitCtx, snapshot.Revision)
+	assert.True(t, changed)
+	assert.Equal(t, "version: v2\n", string(snapshot.Data))
+}
@@ -0,0 +1,154 @@
+package dependency
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workloadsv1alpha "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/reconciler"
+)
+
+// checkDependency reports whether the condition of dep is met.
+func (m *DefaultDependencyManager) checkDependency(
+	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, dep *workloadsv1alpha.Dependency,
+) (bool, error) {
+	switch {
+	case dep.Role != "":
+		return m.checkRoleDependency(ctx, rbg, dep)
+	case dep.Object != nil:
+		return m.checkObjectDependency(ctx, rbg, dep)
+	default:
+		return false, fmt.Errorf("dependency has neither role nor object")
+	}
+}
+
+func (m *DefaultDependencyManager) checkRoleDependency(
+	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, dep *workloadsv1alpha.Dependency,
+) (bool, error) {
+	depRole, err := rbg.GetRole(dep.Role)
+	if err != nil {
+		return false, err
+	}
+	r, err := reconciler.NewWorkloadReconciler(depRole.Workload, m.scheme, m.client)
+	if err != nil {
+		return false, err
+	}
+	status, _, err := r.ConstructRoleStatus(ctx, rbg, depRole)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	switch dep.GetCondition() {
+	case workloadsv1alpha.CreatedDependencyCondition:
+		return true, nil
+	case workloadsv1alpha.ReadyDependencyCondition:
+		return thresholdMet(dep.Threshold, status.ReadyReplicas, status.Replicas)
+	default:
+		return false, fmt.Errorf("condition %s is not supported for role %s", dep.Condition, dep.Role)
+	}
+}
+
+func (m *DefaultDependencyManager) checkObjectDependency(
+	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, dep *workloadsv1alpha.Dependency,
+) (bool, error) {
+	key := types.NamespacedName{Name: dep.Object.Name, Namespace: rbg.Namespace}
+	condition := dep.GetCondition()
+	unsupported := fmt.Errorf("condition %s is not supported for %s %s", condition, dep.Object.Kind, dep.Object.Name)
+
+	switch dep.Object.Kind {
+	case workloadsv1alpha.SecretDependencyObject, workloadsv1alpha.ConfigMapDependencyObject:
+		if condition == workloadsv1alpha.CompletedDependencyCondition {
+			return false, unsupported
+		}
+		// only the existence matters, so the contents of the Secrets are not cached
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(string(dep.Object.Kind)))
+		return m.getObject(ctx, key, obj)
+
+	case workloadsv1alpha.PersistentVolumeClaimDependencyObject:
+		pvc := &corev1.PersistentVolumeClaim{}
+		if found, err := m.getObject(ctx, key, pvc); !found || err != nil {
+			return false, err
+		}
+		switch condition {
+		case workloadsv1alpha.CreatedDependencyCondition:
+			return true, nil
+		case workloadsv1alpha.ReadyDependencyCondition:
+			return pvc.Status.Phase == corev1.ClaimBound, nil
+		}
+		return false, unsupported
+
+	case workloadsv1alpha.JobDependencyObject:
+		job := &batchv1.Job{}
+		if found, err := m.getObject(ctx, key, job); !found || err != nil {
+			return false, err
+		}
+		switch condition {
+		case workloadsv1alpha.CreatedDependencyCondition:
+			return true, nil
+		case workloadsv1alpha.ReadyDependencyCondition:
+			return thresholdMet(dep.Threshold, ptr.Deref(job.Status.Ready, 0), ptr.Deref(job.Spec.Parallelism, 1))
+		case workloadsv1alpha.CompletedDependencyCondition:
+			for _, cond := range job.Status.Conditions {
+				if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return false, unsupported
+
+	case workloadsv1alpha.RoleBasedGroupDependencyObject:
+		other := &workloadsv1alpha.RoleBasedGroup{}
+		if found, err := m.getObject(ctx, key, other); !found || err != nil {
+			return false, err
+		}
+		switch condition {
+		case workloadsv1alpha.CreatedDependencyCondition:
+			return true, nil
+		case workloadsv1alpha.ReadyDependencyCondition:
+			return other.Status.ObservedGeneration == other.Generation &&
+				meta.IsStatusConditionTrue(other.Status.Conditions, string(workloadsv1alpha.RoleBasedGroupReady)), nil
+		}
+		return false, unsupported
+
+	default:
+		return false, fmt.Errorf("unsupported kind %s of dependency %s", dep.Object.Kind, dep.Object.Name)
+	}
+}
+
+// getObject gets the object of key into obj, and reports whether it exists.
+func (m *DefaultDependencyManager) getObject(ctx context.Context, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := m.client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// thresholdMet reports whether ready reaches threshold, a number or a percentage of total capped at total. A nil
+// threshold requires all of total.
+func thresholdMet(threshold *intstr.IntOrString, ready, total int32) (bool, error) {
+	want := int(total)
+	if threshold != nil {
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(threshold, int(total), true)
+		if err != nil {
+			return false, err
+		}
+		want = min(scaled, int(total))
+	}
+	return int(ready) >= want, nil
+}
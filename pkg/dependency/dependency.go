@@ -41,14 +41,14 @@ func (m *DefaultDependencyManager) SortRoles(
 	roleDependency := make(map[string][]string)
 
 	for _, role := range rbg.Spec.Roles {
-		if len(role.Dependencies) > 0 {
-			for _, d := range role.Dependencies {
+		if dependencies := role.DependencyRoles(); len(dependencies) > 0 {
+			for _, d := range dependencies {
 				if !utils.ContainsString(roleNameList, d) {
 					return nil, fmt.Errorf("role [%s] with dependency role [%s] not found in rbg", role.Name, d)
 				}
 			}
 
-			roleDependency[role.Name] = role.Dependencies
+			roleDependency[role.Name] = dependencies
 		} else {
 			roleDependency[role.Name] = []string{}
 		}
@@ -73,6 +73,34 @@ func (m *DefaultDependencyManager) SortRoles(
 
 }
 
+// SortRolesByLevel groups the roles by their level in the dependency graph: the roles without dependencies are at
+// level 0, and the other roles are one level after their deepest dependency. The roles of a level only depend on the
+// roles of the previous levels, so they can be reconciled in parallel.
+func (m *DefaultDependencyManager) SortRolesByLevel(
+	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup,
+) ([][]*workloadsv1alpha.RoleSpec, error) {
+	sortedRoles, err := m.SortRoles(ctx, rbg)
+	if err != nil {
+		return nil, err
+	}
+
+	// roles are sorted by dependency order, so the levels of the dependencies of a role are always known
+	levelOf := make(map[string]int, len(sortedRoles))
+	var levels [][]*workloadsv1alpha.RoleSpec
+	for _, role := range sortedRoles {
+		level := 0
+		for _, dep := range role.DependencyRoles() {
+			level = max(level, levelOf[dep]+1)
+		}
+		levelOf[role.Name] = level
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], role)
+	}
+	return levels, nil
+}
+
 func (m *DefaultDependencyManager) CheckDependencyReady(
 	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, role *workloadsv1alpha.RoleSpec,
 ) (bool, error) {
@@ -95,9 +123,44 @@ func (m *DefaultDependencyManager) CheckDependencyReady(
 		}
 	}
 
+	for i := range role.DependsOn {
+		met, err := m.checkDependency(ctx, rbg, &role.DependsOn[i])
+		if err != nil || !met {
+			return false, err
+		}
+	}
 	return true, nil
 }
 
+// SortDependentRoles returns the role and the roles depending on it, sorted by dependency order.
+// If transitive is false, only the roles which declare the role in their dependencies are returned.
+func (m *DefaultDependencyManager) SortDependentRoles(
+	ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup, roleName string, transitive bool,
+) ([]*workloadsv1alpha.RoleSpec, error) {
+	sortedRoles, err := m.SortRoles(ctx, rbg)
+	if err != nil {
+		return nil, err
+	}
+
+	// roles are sorted by dependency order, so the dependencies of a role are always visited before the role
+	affected := map[string]bool{roleName: true}
+	var ret []*workloadsv1alpha.RoleSpec
+	for _, role := range sortedRoles {
+		if role.Name != roleName {
+			for _, dep := range role.DependencyRoles() {
+				if dep == roleName || (transitive && affected[dep]) {
+					affected[role.Name] = true
+					break
+				}
+			}
+		}
+		if affected[role.Name] {
+			ret = append(ret, role)
+		}
+	}
+	return ret, nil
+}
+
 // Use Depth-First Search (DFS) to build a topological sort and check for cycles
 func dependencyOrder(ctx context.Context, dependencies map[string][]string) ([]string, error) {
 	logger := log.FromContext(ctx)
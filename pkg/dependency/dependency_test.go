@@ -5,8 +5,19 @@ import (
 	"reflect"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/test/wrappers"
 )
 
 // TestDependencyOrder tests the DependencyOrder function with various dependency scenarios
@@ -83,3 +94,249 @@ func TestDependencyOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestSortDependentRoles(t *testing.T) {
+	// gateway -> decode -> prefill, router has no dependency
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(
+		[]workloadsv1alpha1.RoleSpec{
+			wrappers.BuildBasicRole("gateway").WithDependencies([]string{"decode"}).Obj(),
+			wrappers.BuildBasicRole("decode").WithDependencies([]string{"prefill"}).Obj(),
+			wrappers.BuildBasicRole("prefill").Obj(),
+			wrappers.BuildBasicRole("router").Obj(),
+		},
+	).Obj()
+
+	tests := []struct {
+		name       string
+		roleName   string
+		transitive bool
+		want       []string
+	}{
+		{
+			name:       "transitive dependents",
+			roleName:   "prefill",
+			transitive: true,
+			want:       []string{"prefill", "decode", "gateway"},
+		},
+		{
+			name:       "direct dependents",
+			roleName:   "prefill",
+			transitive: false,
+			want:       []string{"prefill", "decode"},
+		},
+		{
+			name:       "no dependents",
+			roleName:   "router",
+			transitive: true,
+			want:       []string{"router"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := log.IntoContext(context.TODO(), klog.NewKlogr())
+			m := NewDefaultDependencyManager(nil, nil)
+			roles, err := m.SortDependentRoles(ctx, rbg, tt.roleName, tt.transitive)
+			if err != nil {
+				t.Fatalf("SortDependentRoles() error = %v", err)
+			}
+			got := make([]string, 0, len(roles))
+			for _, role := range roles {
+				got = append(got, role.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortDependentRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDependencyReady_DependsOn(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	// the workload of the role "prefill" has 4 replicas, 2 of them ready
+	prefill := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg-prefill", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(4))},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"}}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	pendingPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "download", Namespace: "default"},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}},
+	}
+	runningJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "warmup", Namespace: "default"},
+		Spec:       batchv1.JobSpec{Parallelism: ptr.To(int32(2))},
+		Status:     batchv1.JobStatus{Ready: ptr.To(int32(1))},
+	}
+	otherRBG := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: "default"},
+		Status: workloadsv1alpha1.RoleBasedGroupStatus{Conditions: []metav1.Condition{
+			{Type: string(workloadsv1alpha1.RoleBasedGroupReady), Status: metav1.ConditionTrue},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(prefill, secret, pvc, pendingPVC, job, runningJob, otherRBG).Build()
+
+	object := func(kind workloadsv1alpha1.DependencyObjectKind, name string) *workloadsv1alpha1.DependencyObject {
+		return &workloadsv1alpha1.DependencyObject{Kind: kind, Name: name}
+	}
+	tests := []struct {
+		name    string
+		dep     workloadsv1alpha1.Dependency
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "role ready requires all replicas by default",
+			dep:  workloadsv1alpha1.Dependency{Role: "prefill"},
+			want: false,
+		},
+		{
+			name: "role ready with a percentage threshold",
+			dep:  workloadsv1alpha1.Dependency{Role: "prefill", Threshold: ptr.To(intstr.FromString("50%"))},
+			want: true,
+		},
+		{
+			name: "role ready with a count threshold",
+			dep:  workloadsv1alpha1.Dependency{Role: "prefill", Threshold: ptr.To(intstr.FromInt32(3))},
+			want: false,
+		},
+		{
+			name: "role created",
+			dep: workloadsv1alpha1.Dependency{
+				Role: "prefill", Condition: workloadsv1alpha1.CreatedDependencyCondition,
+			},
+			want: true,
+		},
+		{
+			name: "role completed is not supported",
+			dep: workloadsv1alpha1.Dependency{
+				Role: "prefill", Condition: workloadsv1alpha1.CompletedDependencyCondition,
+			},
+			wantErr: true,
+		},
+		{
+			name: "secret exists",
+			dep:  workloadsv1alpha1.Dependency{Object: object(workloadsv1alpha1.SecretDependencyObject, "token")},
+			want: true,
+		},
+		{
+			name: "secret not found",
+			dep:  workloadsv1alpha1.Dependency{Object: object(workloadsv1alpha1.SecretDependencyObject, "missing")},
+			want: false,
+		},
+		{
+			name: "pvc bound",
+			dep: workloadsv1alpha1.Dependency{
+				Object: object(workloadsv1alpha1.PersistentVolumeClaimDependencyObject, "model"),
+			},
+			want: true,
+		},
+		{
+			name: "pvc pending",
+			dep: workloadsv1alpha1.Dependency{
+				Object: object(workloadsv1alpha1.PersistentVolumeClaimDependencyObject, "cache"),
+			},
+			want: false,
+		},
+		{
+			name: "job complete",
+			dep: workloadsv1alpha1.Dependency{
+				Object:    object(workloadsv1alpha1.JobDependencyObject, "download"),
+				Condition: workloadsv1alpha1.CompletedDependencyCondition,
+			},
+			want: true,
+		},
+		{
+			name: "job not complete",
+			dep: workloadsv1alpha1.Dependency{
+				Object:    object(workloadsv1alpha1.JobDependencyObject, "warmup"),
+				Condition: workloadsv1alpha1.CompletedDependencyCondition,
+			},
+			want: false,
+		},
+		{
+			name: "job ready with a threshold",
+			dep: workloadsv1alpha1.Dependency{
+				Object:    object(workloadsv1alpha1.JobDependencyObject, "warmup"),
+				Threshold: ptr.To(intstr.FromString("50%")),
+			},
+			want: true,
+		},
+		{
+			name: "rbg ready",
+			dep: workloadsv1alpha1.Dependency{
+				Object: object(workloadsv1alpha1.RoleBasedGroupDependencyObject, "gateway"),
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(
+				[]workloadsv1alpha1.RoleSpec{
+					wrappers.BuildBasicRole("prefill").WithWorkload(workloadsv1alpha1.DeploymentWorkloadType).Obj(),
+					wrappers.BuildBasicRole("decode").WithDependsOn([]workloadsv1alpha1.Dependency{tt.dep}).Obj(),
+				},
+			).Obj()
+			role, _ := rbg.GetRole("decode")
+			m := NewDefaultDependencyManager(scheme, c)
+			got, err := m.CheckDependencyReady(context.TODO(), rbg, role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDependencyReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CheckDependencyReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRolesByLevel(t *testing.T) {
+	// gateway -> decode -> prefill, gateway -> router, cache has no dependency
+	rbg := wrappers.BuildBasicRoleBasedGroup("test-rbg", "default").WithRoles(
+		[]workloadsv1alpha1.RoleSpec{
+			wrappers.BuildBasicRole("gateway").WithDependencies([]string{"decode"}).
+				WithDependsOn([]workloadsv1alpha1.Dependency{{Role: "router"}}).Obj(),
+			wrappers.BuildBasicRole("decode").WithDependsOn([]workloadsv1alpha1.Dependency{
+				{Role: "prefill", Threshold: ptr.To(intstr.FromString("50%"))},
+			}).Obj(),
+			wrappers.BuildBasicRole("prefill").Obj(),
+			wrappers.BuildBasicRole("router").Obj(),
+			wrappers.BuildBasicRole("cache").Obj(),
+		},
+	).Obj()
+
+	ctx := log.IntoContext(context.TODO(), klog.NewKlogr())
+	levels, err := NewDefaultDependencyManager(nil, nil).SortRolesByLevel(ctx, rbg)
+	if err != nil {
+		t.Fatalf("SortRolesByLevel() error = %v", err)
+	}
+	got := make([][]string, 0, len(levels))
+	for _, roles := range levels {
+		names := make([]string, 0, len(roles))
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"cache", "prefill", "router"}, {"decode"}, {"gateway"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortRolesByLevel() = %v, want %v", got, want)
+	}
+}
@@ -8,7 +8,11 @@ import (
 
 type DependencyManager interface {
 	SortRoles(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([]*workloadsv1alpha1.RoleSpec, error)
+	SortRolesByLevel(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) ([][]*workloadsv1alpha1.RoleSpec, error)
 	CheckDependencyReady(
 		ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
 	) (bool, error)
+	SortDependentRoles(
+		ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, roleName string, transitive bool,
+	) ([]*workloadsv1alpha1.RoleSpec, error)
 }
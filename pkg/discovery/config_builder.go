@@ -1,8 +1,12 @@
 package discovery
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,17 +16,30 @@ import (
 	"sigs.k8s.io/yaml"
 
 	corev1 "k8s.io/api/core/v1"
+	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
 	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 )
 
+// ConfigSchemaVersion is the version of the schema of ClusterConfig, it is bumped on incompatible changes.
+const ConfigSchemaVersion = "v1"
+
 type ConfigBuilder struct {
 	rbg  *workloadsv1alpha1.RoleBasedGroup
 	role *workloadsv1alpha1.RoleSpec
+	// pods of rbg, the instances are built from them if rbg discovers pods
+	pods []corev1.Pod
+	// template renders the config if the format of role is Template
+	template string
+	// static builds the instances from the spec of rbg even if rbg discovers pods
+	static bool
+	// readyOnly skips the pods which are not ready even if the discovery policy of rbg includes them
+	readyOnly bool
 }
 
 type ClusterConfig struct {
-	Group GroupInfo `json:"group"`
-	Roles RolesInfo `json:"roles"`
+	Version string    `json:"version"`
+	Group   GroupInfo `json:"group"`
+	Roles   RolesInfo `json:"roles"`
 }
 
 type GroupInfo struct {
@@ -41,10 +58,33 @@ type RoleInstances struct {
 type Instance struct {
 	Address string           `json:"address"`
 	Ports   map[string]int32 `json:"ports,omitempty"` // Key: port name, Value: port number
+
+	// The fields below are only set for the instances observed from pods
+	Name    string     `json:"name,omitempty"`
+	IP      string     `json:"ip,omitempty"`
+	Ready   *bool      `json:"ready,omitempty"`
+	Workers []Instance `json:"workers,omitempty"` // The workers of a LeaderWorkerSet group, the instance is the leader
 }
 
+// Build renders the config in the discovery format of the role.
 func (b *ConfigBuilder) Build() ([]byte, error) {
-	config := ClusterConfig{
+	config := b.buildClusterConfig()
+
+	switch b.role.GetDiscoveryFormat() {
+	case workloadsv1alpha1.JSONDiscoveryFormat:
+		return json.MarshalIndent(config, "", "  ")
+	case workloadsv1alpha1.EnvDiscoveryFormat:
+		return b.buildEnvFile(config), nil
+	case workloadsv1alpha1.TemplateDiscoveryFormat:
+		return renderTemplate(b.template, config)
+	default:
+		return yaml.Marshal(config)
+	}
+}
+
+func (b *ConfigBuilder) buildClusterConfig() ClusterConfig {
+	return ClusterConfig{
+		Version: ConfigSchemaVersion,
 		Group: GroupInfo{
 			Name:  b.rbg.Name,
 			Size:  len(b.rbg.Spec.Roles),
@@ -52,7 +92,74 @@ func (b *ConfigBuilder) Build() ([]byte, error) {
 		},
 		Roles: b.buildRolesInfo(),
 	}
-	return yaml.Marshal(config)
+}
+
+// buildEnvFile writes config as KEY=value lines, e.g.
+//
+//	CONFIG_VERSION=v1
+//	GROUP_NAME=rbg
+//	GROUP_SIZE=2
+//	GROUP_ROLES=prefill,decode
+//	ROLE_PREFILL_SIZE=2
+//	ROLE_PREFILL_ADDRESSES=prefill-0.rbg-prefill,prefill-1.rbg-prefill
+//	ROLE_PREFILL_PORT_HTTP=8000
+func (b *ConfigBuilder) buildEnvFile(config ClusterConfig) []byte {
+	var buf bytes.Buffer
+	writeEnv := func(key, value string) {
+		fmt.Fprintf(&buf, "%s=%s\n", key, value)
+	}
+	writeEnv("CONFIG_VERSION", config.Version)
+	writeEnv("GROUP_NAME", config.Group.Name)
+	writeEnv("GROUP_SIZE", fmt.Sprintf("%d", config.Group.Size))
+	writeEnv("GROUP_ROLES", strings.Join(config.Group.Roles, ","))
+	for _, role := range b.rbg.Spec.Roles {
+		prefix := "ROLE_" + envKey(role.Name)
+		instances := config.Roles[role.Name]
+		addresses := make([]string, 0, len(instances.Instances))
+		for _, instance := range instances.Instances {
+			addresses = append(addresses, instance.Address)
+		}
+		writeEnv(prefix+"_SIZE", fmt.Sprintf("%d", instances.Size))
+		writeEnv(prefix+"_ADDRESSES", strings.Join(addresses, ","))
+		for _, port := range role.ServicePorts {
+			writeEnv(prefix+"_PORT_"+strings.ToUpper(generatePortKey(port)), fmt.Sprintf("%d", port.Port))
+		}
+	}
+	return buf.Bytes()
+}
+
+func envKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// templateFuncs are the functions available in the templates rendered with the discovery config.
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"toJSON": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"addresses": func(instances []Instance) []string {
+		addresses := make([]string, 0, len(instances))
+		for _, instance := range instances {
+			addresses = append(addresses, instance.Address)
+		}
+		return addresses
+	},
+}
+
+// renderTemplate executes the Go text/template tmpl with data. Referencing a missing role is an error rather than
+// an empty value, so that a typo in the template is reported.
+func renderTemplate(tmpl string, data interface{}) ([]byte, error) {
+	t, err := template.New("discovery").Option("missingkey=error").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render template: %w", err)
+	}
+	return buf.Bytes(), nil
 }
 
 func (b *ConfigBuilder) getRoleNames() []string {
@@ -75,6 +182,10 @@ func (b *ConfigBuilder) buildRolesInfo() RolesInfo {
 }
 
 func (b *ConfigBuilder) buildInstances(role *workloadsv1alpha1.RoleSpec) []Instance {
+	if b.rbg.DiscoverPods() && !b.static {
+		return b.buildPodInstances(role)
+	}
+
 	instances := make([]Instance, 0, *role.Replicas)
 	serviceName := b.rbg.GetWorkloadName(role)
 
@@ -94,6 +205,99 @@ func (b *ConfigBuilder) buildInstances(role *workloadsv1alpha1.RoleSpec) []Insta
 	return instances
 }
 
+// buildPodInstances builds the instances of role from its observed pods. The pods of a LeaderWorkerSet role are
+// grouped into one instance per leader, and the group is ready when all its pods are ready.
+func (b *ConfigBuilder) buildPodInstances(role *workloadsv1alpha1.RoleSpec) []Instance {
+	includeNotReady := b.rbg.Spec.Discovery != nil && b.rbg.Spec.Discovery.IncludeNotReady && !b.readyOnly
+
+	pods := make([]corev1.Pod, 0)
+	for _, pod := range b.pods {
+		// pods without IP can not be addressed yet
+		if pod.Labels[workloadsv1alpha1.SetRoleLabelKey] != role.Name || pod.DeletionTimestamp != nil ||
+			pod.Status.PodIP == "" {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	// sort the pods by the length of name first, so that the pods of sts and lws are ordered by index
+	sort.Slice(pods, func(i, j int) bool {
+		if len(pods[i].Name) != len(pods[j].Name) {
+			return len(pods[i].Name) < len(pods[j].Name)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	instances := make([]Instance, 0, len(pods))
+	if role.Workload.String() != workloadsv1alpha1.LeaderWorkerSetWorkloadType {
+		for i := range pods {
+			instance := b.podInstance(role, &pods[i])
+			if *instance.Ready || includeNotReady {
+				instances = append(instances, instance)
+			}
+		}
+		return instances
+	}
+
+	workers := make(map[string][]Instance)
+	for i := range pods {
+		if pods[i].Labels[lwsv1.WorkerIndexLabelKey] != "0" {
+			group := pods[i].Labels[lwsv1.GroupIndexLabelKey]
+			workers[group] = append(workers[group], b.podInstance(role, &pods[i]))
+		}
+	}
+	groupSize := 1
+	if role.LeaderWorkerSet.Size != nil {
+		groupSize = int(*role.LeaderWorkerSet.Size)
+	}
+	for i := range pods {
+		if pods[i].Labels[lwsv1.WorkerIndexLabelKey] != "0" {
+			continue
+		}
+		leader := b.podInstance(role, &pods[i])
+		leader.Workers = workers[pods[i].Labels[lwsv1.GroupIndexLabelKey]]
+		ready := *leader.Ready && len(leader.Workers) == groupSize-1
+		for _, worker := range leader.Workers {
+			ready = ready && *worker.Ready
+		}
+		leader.Ready = &ready
+		if ready || includeNotReady {
+			instances = append(instances, leader)
+		}
+	}
+	return instances
+}
+
+// ReadyInstances returns the ready instances of role built from the pods of rbg as in the discovery config, whatever
+// the discovery source of rbg.
+func ReadyInstances(
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, pods []corev1.Pod,
+) []Instance {
+	b := &ConfigBuilder{rbg: rbg, pods: pods, readyOnly: true}
+	return b.buildPodInstances(role)
+}
+
+func (b *ConfigBuilder) podInstance(role *workloadsv1alpha1.RoleSpec, pod *corev1.Pod) Instance {
+	address := pod.Status.PodIP
+	// pods of sts and lws have a stable DNS name resolved by their headless service
+	policy := b.rbg.Spec.Discovery
+	if policy != nil && policy.AddressType == workloadsv1alpha1.DNSDiscoveryAddressType &&
+		pod.Spec.Hostname != "" && pod.Spec.Subdomain != "" {
+		address = fmt.Sprintf("%s.%s", pod.Spec.Hostname, pod.Spec.Subdomain)
+	}
+	ready := utils.PodRunningAndReady(*pod)
+	instance := Instance{
+		Address: address,
+		Ports:   make(map[string]int32),
+		Name:    pod.Name,
+		IP:      pod.Status.PodIP,
+		Ready:   &ready,
+	}
+	for _, port := range role.ServicePorts {
+		instance.Ports[generatePortKey(port)] = port.Port
+	}
+	return instance
+}
+
 func generatePortKey(port corev1.ServicePort) string {
 	if port.Name != "" {
 		return strings.ToLower(strings.ReplaceAll(port.Name, "-", "_"))
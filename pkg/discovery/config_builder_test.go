@@ -0,0 +1,253 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	lwsv1 "sigs.k8s.io/lws/api/leaderworkerset/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func buildDiscoveryPod(name, role, ip string, ready bool, labels map[string]string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha1.SetRoleLabelKey: role,
+			},
+		},
+		Spec: corev1.PodSpec{Hostname: name, Subdomain: "test-rbg-" + role},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: ip,
+		},
+	}
+	for k, v := range labels {
+		pod.Labels[k] = v
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestConfigBuilder_buildPodInstances(t *testing.T) {
+	sts := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	lws := workloadsv1alpha1.WorkloadSpec{APIVersion: "leaderworkerset.x-k8s.io/v1", Kind: "LeaderWorkerSet"}
+	ports := []corev1.ServicePort{{Name: "http-port", Port: 8000}}
+	worker := func(group, index string) map[string]string {
+		return map[string]string{lwsv1.GroupIndexLabelKey: group, lwsv1.WorkerIndexLabelKey: index}
+	}
+
+	pods := []corev1.Pod{
+		buildDiscoveryPod("test-rbg-prefill-10", "prefill", "10.0.0.10", true, nil),
+		buildDiscoveryPod("test-rbg-prefill-2", "prefill", "10.0.0.2", true, nil),
+		buildDiscoveryPod("test-rbg-prefill-3", "prefill", "10.0.0.3", false, nil),
+		// not scheduled yet
+		buildDiscoveryPod("test-rbg-prefill-4", "prefill", "", false, nil),
+		buildDiscoveryPod("test-rbg-decode-0", "decode", "10.0.1.0", true, worker("0", "0")),
+		buildDiscoveryPod("test-rbg-decode-0-1", "decode", "10.0.1.1", true, worker("0", "1")),
+		buildDiscoveryPod("test-rbg-decode-1", "decode", "10.0.2.0", true, worker("1", "0")),
+		buildDiscoveryPod("test-rbg-decode-1-1", "decode", "10.0.2.1", false, worker("1", "1")),
+	}
+
+	tests := []struct {
+		name          string
+		policy        workloadsv1alpha1.DiscoveryPolicy
+		role          workloadsv1alpha1.RoleSpec
+		expectAddress []string
+		expectReady   []bool
+		expectWorkers [][]string
+	}{
+		{
+			name:          "Ready pods by IP",
+			policy:        workloadsv1alpha1.DiscoveryPolicy{Source: workloadsv1alpha1.PodsDiscoverySource},
+			role:          workloadsv1alpha1.RoleSpec{Name: "prefill", Workload: sts, ServicePorts: ports},
+			expectAddress: []string{"10.0.0.2", "10.0.0.10"},
+			expectReady:   []bool{true, true},
+		},
+		{
+			name: "All pods by DNS",
+			policy: workloadsv1alpha1.DiscoveryPolicy{
+				Source:          workloadsv1alpha1.PodsDiscoverySource,
+				AddressType:     workloadsv1alpha1.DNSDiscoveryAddressType,
+				IncludeNotReady: true,
+			},
+			role: workloadsv1alpha1.RoleSpec{Name: "prefill", Workload: sts, ServicePorts: ports},
+			expectAddress: []string{
+				"test-rbg-prefill-2.test-rbg-prefill",
+				"test-rbg-prefill-3.test-rbg-prefill",
+				"test-rbg-prefill-10.test-rbg-prefill",
+			},
+			expectReady: []bool{true, false, true},
+		},
+		{
+			name: "LeaderWorkerSet groups",
+			policy: workloadsv1alpha1.DiscoveryPolicy{
+				Source:          workloadsv1alpha1.PodsDiscoverySource,
+				IncludeNotReady: true,
+			},
+			role: workloadsv1alpha1.RoleSpec{
+				Name: "decode", Workload: lws, ServicePorts: ports,
+				LeaderWorkerSet: workloadsv1alpha1.LeaderWorkerTemplate{Size: ptr.To(int32(2))},
+			},
+			expectAddress: []string{"10.0.1.0", "10.0.2.0"},
+			expectReady:   []bool{true, false},
+			expectWorkers: [][]string{{"10.0.1.1"}, {"10.0.2.1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := &workloadsv1alpha1.RoleBasedGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg"},
+				Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+					Roles:     []workloadsv1alpha1.RoleSpec{tt.role},
+					Discovery: &tt.policy,
+				},
+			}
+			b := &ConfigBuilder{rbg: rbg, role: &tt.role, pods: pods}
+			instances := b.buildInstances(&tt.role)
+
+			var addresses []string
+			var ready []bool
+			var workers [][]string
+			for _, instance := range instances {
+				addresses = append(addresses, instance.Address)
+				ready = append(ready, *instance.Ready)
+				assert.Equal(t, map[string]int32{"http_port": 8000}, instance.Ports)
+				if instance.Workers != nil {
+					var workerAddresses []string
+					for _, w := range instance.Workers {
+						workerAddresses = append(workerAddresses, w.Address)
+					}
+					workers = append(workers, workerAddresses)
+				}
+			}
+			assert.Equal(t, tt.expectAddress, addresses)
+			assert.Equal(t, tt.expectReady, ready)
+			assert.Equal(t, tt.expectWorkers, workers)
+		})
+	}
+}
+
+func TestConfigBuilder_Build(t *testing.T) {
+	buildRole := func(name string, discovery *workloadsv1alpha1.RoleDiscoveryConfig) workloadsv1alpha1.RoleSpec {
+		return workloadsv1alpha1.RoleSpec{
+			Name:         name,
+			Replicas:     ptr.To(int32(2)),
+			Workload:     workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"},
+			ServicePorts: []corev1.ServicePort{{Name: "http", Port: 8000}},
+			Discovery:    discovery,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		discovery *workloadsv1alpha1.RoleDiscoveryConfig
+		template  string
+		expect    string
+		expectErr bool
+	}{
+		{
+			name: "YAML by default",
+			expect: `group:
+  name: test-rbg
+  roles:
+  - prefill
+  size: 1
+roles:
+  prefill:
+    instances:
+    - address: prefill-0.test-rbg-prefill
+      ports:
+        http: 8000
+    - address: prefill-1.test-rbg-prefill
+      ports:
+        http: 8000
+    size: 2
+version: v1
+`,
+		},
+		{
+			name:      "JSON",
+			discovery: &workloadsv1alpha1.RoleDiscoveryConfig{Format: workloadsv1alpha1.JSONDiscoveryFormat},
+			expect: `{
+  "version": "v1",
+  "group": {
+    "name": "test-rbg",
+    "size": 1,
+    "roles": [
+      "prefill"
+    ]
+  },
+  "roles": {
+    "prefill": {
+      "size": 2,
+      "instances": [
+        {
+          "address": "prefill-0.test-rbg-prefill",
+          "ports": {
+            "http": 8000
+          }
+        },
+        {
+          "address": "prefill-1.test-rbg-prefill",
+          "ports": {
+            "http": 8000
+          }
+        }
+      ]
+    }
+  }
+}`,
+		},
+		{
+			name:      "Env file",
+			discovery: &workloadsv1alpha1.RoleDiscoveryConfig{Format: workloadsv1alpha1.EnvDiscoveryFormat},
+			expect: `CONFIG_VERSION=v1
+GROUP_NAME=test-rbg
+GROUP_SIZE=1
+GROUP_ROLES=prefill
+ROLE_PREFILL_SIZE=2
+ROLE_PREFILL_ADDRESSES=prefill-0.test-rbg-prefill,prefill-1.test-rbg-prefill
+ROLE_PREFILL_PORT_HTTP=8000
+`,
+		},
+		{
+			name:      "Template",
+			discovery: &workloadsv1alpha1.RoleDiscoveryConfig{Format: workloadsv1alpha1.TemplateDiscoveryFormat},
+			template: `--dist-init-addr {{ (index .Roles.prefill.Instances 0).Address }}:{{ ` +
+				`(index .Roles.prefill.Instances 0).Ports.http }} --nnodes {{ .Roles.prefill.Size }}`,
+			expect: "--dist-init-addr prefill-0.test-rbg-prefill:8000 --nnodes 2",
+		},
+		{
+			name:      "Template referencing a missing role",
+			discovery: &workloadsv1alpha1.RoleDiscoveryConfig{Format: workloadsv1alpha1.TemplateDiscoveryFormat},
+			template:  `{{ .Roles.decode.Size }}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := buildRole("prefill", tt.discovery)
+			rbg := &workloadsv1alpha1.RoleBasedGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg"},
+				Spec:       workloadsv1alpha1.RoleBasedGroupSpec{Roles: []workloadsv1alpha1.RoleSpec{role}},
+			}
+			b := &ConfigBuilder{rbg: rbg, role: &role, template: tt.template}
+			data, err := b.Build()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, string(data))
+		})
+	}
+}
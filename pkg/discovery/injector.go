@@ -31,6 +31,14 @@ type GroupInfoInjector interface {
 		context context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup,
 		role *workloadsv1alpha1.RoleSpec,
 	) error
+	RenderTemplates(
+		context context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup,
+		role *workloadsv1alpha1.RoleSpec,
+	) error
+	InjectStartupBarrier(
+		context context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup,
+		role *workloadsv1alpha1.RoleSpec,
+	) error
 }
 
 type DefaultInjector struct {
@@ -57,12 +65,30 @@ func (i *DefaultInjector) InjectConfig(
 		rbg:  rbg,
 		role: role,
 	}
+	if rbg.DiscoverPods() {
+		podList := &corev1.PodList{}
+		if err := i.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+			client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name},
+		); err != nil {
+			return err
+		}
+		builder.pods = podList.Items
+	}
+
+	if role.GetDiscoveryFormat() == workloadsv1alpha1.TemplateDiscoveryFormat {
+		tmpl, err := i.getDiscoveryTemplate(ctx, rbg, role)
+		if err != nil {
+			return err
+		}
+		builder.template = tmpl
+	}
 
 	const (
-		volumeName = "rbg-cluster-config"
-		mountPath  = "/etc/rbg"
-		configKey  = "config.yaml"
+		volumeName    = "rbg-cluster-config"
+		setVolumeName = "rbg-set-config"
 	)
+	mountPath := role.GetDiscoveryMountPath()
+	configKey := role.GetDiscoveryFileName()
 
 	configData, err := builder.Build()
 	if err != nil {
@@ -112,6 +138,21 @@ func (i *DefaultInjector) InjectConfig(
 		}
 	}
 
+	mountConfigMap(podSpec, role, volumeName, rbg.GetWorkloadName(role), configKey, mountPath, false)
+
+	// the ConfigMap of the rbgset is published by the rbgset controller, it may not exist yet
+	if rbgsetName, ok := rbg.Labels[workloadsv1alpha1.SetRBGSetNameLabelKey]; ok && role.MountSetDiscoveryConfig() {
+		mountConfigMap(podSpec, role, setVolumeName, SetConfigMapName(rbgsetName), SetConfigKey,
+			role.GetSetDiscoveryMountPath(), true)
+	}
+	return nil
+}
+
+// mountConfigMap mounts the key of the ConfigMap cmName as a file at mountPath into the injection targets of podSpec.
+func mountConfigMap(
+	podSpec *corev1.PodTemplateSpec, role *workloadsv1alpha1.RoleSpec,
+	volumeName, cmName, key, mountPath string, optional bool,
+) {
 	volumeExists := false
 	for _, vol := range podSpec.Spec.Volumes {
 		if vol.Name == volumeName {
@@ -120,25 +161,26 @@ func (i *DefaultInjector) InjectConfig(
 		}
 	}
 	if !volumeExists {
-		podSpec.Spec.Volumes = append(
-			podSpec.Spec.Volumes, corev1.Volume{
-				Name: volumeName,
-				VolumeSource: corev1.VolumeSource{
-					ConfigMap: &corev1.ConfigMapVolumeSource{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: rbg.GetWorkloadName(role),
-						},
-						Items: []corev1.KeyToPath{
-							{Key: configKey, Path: configKey},
-						},
+		volume := corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName,
+					},
+					Items: []corev1.KeyToPath{
+						{Key: key, Path: key},
 					},
 				},
 			},
-		)
+		}
+		if optional {
+			volume.ConfigMap.Optional = &optional
+		}
+		podSpec.Spec.Volumes = append(podSpec.Spec.Volumes, volume)
 	}
 
-	for i := range podSpec.Spec.Containers {
-		container := &podSpec.Spec.Containers[i]
+	for _, container := range injectionTargets(podSpec, role) {
 		mountExists := false
 		for _, vm := range container.VolumeMounts {
 			if vm.Name == volumeName && vm.MountPath == mountPath {
@@ -156,7 +198,29 @@ func (i *DefaultInjector) InjectConfig(
 			)
 		}
 	}
-	return nil
+}
+
+// getDiscoveryTemplate returns the template rendering the discovery config of role.
+func (i *DefaultInjector) getDiscoveryTemplate(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec,
+) (string, error) {
+	if role.Discovery.Template == nil {
+		return "", fmt.Errorf("discovery template of role %s is not set", role.Name)
+	}
+	ref := role.Discovery.Template
+	key := ref.Key
+	if key == "" {
+		key = "template"
+	}
+	cm := &corev1.ConfigMap{}
+	if err := i.client.Get(ctx, types.NamespacedName{Name: ref.ConfigMapName, Namespace: rbg.Namespace}, cm); err != nil {
+		return "", fmt.Errorf("get discovery template of role %s: %w", role.Name, err)
+	}
+	tmpl, ok := cm.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in discovery template ConfigMap %s", key, ref.ConfigMapName)
+	}
+	return tmpl, nil
 }
 
 func (i *DefaultInjector) InjectEnv(
@@ -169,16 +233,20 @@ func (i *DefaultInjector) InjectEnv(
 	}
 
 	envVars := builder.Build()
+	override := role.OverrideInjectedEnv()
 
-	for idx := range podSpec.Spec.Containers {
-		container := &podSpec.Spec.Containers[idx]
+	for _, container := range injectionTargets(podSpec, role) {
 		// 1. Convert env to Map to remove duplicates
 		existingEnv := make(map[string]corev1.EnvVar)
 		for _, e := range container.Env {
 			existingEnv[e.Name] = e
 		}
 		for _, newEnv := range envVars {
-			existingEnv[newEnv.Name] = newEnv // Overwrite env.Value if the name exists
+			// Keep the env defined by the user unless the role asks to override it
+			if _, exists := existingEnv[newEnv.Name]; exists && !override {
+				continue
+			}
+			existingEnv[newEnv.Name] = newEnv
 		}
 		// 2. Convert back to slice
 		mergedEnv := make([]corev1.EnvVar, 0, len(existingEnv))
@@ -203,3 +271,30 @@ func (i *DefaultInjector) InjectSidecar(
 	builder := NewSidecarBuilder(i.client, rbg, role)
 	return builder.Build(ctx, podSpec)
 }
+
+// injectionTargets returns the containers of podSpec which the config and the env vars are injected into: the
+// containers and init containers named by the injection policy of role, or all containers if none is named.
+func injectionTargets(podSpec *corev1.PodTemplateSpec, role *workloadsv1alpha1.RoleSpec) []*corev1.Container {
+	var names []string
+	if role.Injection != nil {
+		names = role.Injection.Containers
+	}
+	targets := make([]*corev1.Container, 0, len(podSpec.Spec.Containers))
+	if len(names) == 0 {
+		for i := range podSpec.Spec.Containers {
+			targets = append(targets, &podSpec.Spec.Containers[i])
+		}
+		return targets
+	}
+	for i := range podSpec.Spec.InitContainers {
+		if utils.ContainsString(names, podSpec.Spec.InitContainers[i].Name) {
+			targets = append(targets, &podSpec.Spec.InitContainers[i])
+		}
+	}
+	for i := range podSpec.Spec.Containers {
+		if utils.ContainsString(names, podSpec.Spec.Containers[i].Name) {
+			targets = append(targets, &podSpec.Spec.Containers[i])
+		}
+	}
+	return targets
+}
@@ -392,3 +392,126 @@ func TestInjectSidecar(t *testing.T) {
 		)
 	}
 }
+
+func TestGetDiscoveryTemplate(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(testScheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "sglang-template", Namespace: "default"},
+			Data:       map[string]string{"template": "{{ .Group.Name }}", "args": "--nnodes {{ .Group.Size }}"},
+		},
+	).Build()
+	rbg := &workloadsv1alpha.RoleBasedGroup{ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"}}
+
+	tests := []struct {
+		name     string
+		template *workloadsv1alpha.DiscoveryTemplate
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "default key",
+			template: &workloadsv1alpha.DiscoveryTemplate{ConfigMapName: "sglang-template"},
+			want:     "{{ .Group.Name }}",
+		},
+		{
+			name:     "custom key",
+			template: &workloadsv1alpha.DiscoveryTemplate{ConfigMapName: "sglang-template", Key: "args"},
+			want:     "--nnodes {{ .Group.Size }}",
+		},
+		{
+			name:     "missing key",
+			template: &workloadsv1alpha.DiscoveryTemplate{ConfigMapName: "sglang-template", Key: "vllm"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing configmap",
+			template: &workloadsv1alpha.DiscoveryTemplate{ConfigMapName: "vllm-template"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &workloadsv1alpha.RoleSpec{
+				Name: "prefill",
+				Discovery: &workloadsv1alpha.RoleDiscoveryConfig{
+					Format:   workloadsv1alpha.TemplateDiscoveryFormat,
+					Template: tt.template,
+				},
+			}
+			injector := NewDefaultInjector(testScheme, fakeClient)
+			got, err := injector.getDiscoveryTemplate(context.TODO(), rbg, role)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getDiscoveryTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("getDiscoveryTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectEnv(t *testing.T) {
+	rbg := &workloadsv1alpha.RoleBasedGroup{ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"}}
+	podSpec := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "download"}},
+			Containers: []corev1.Container{
+				{Name: "engine", Env: []corev1.EnvVar{{Name: "ROLE_NAME", Value: "user-defined"}}},
+				{Name: "exporter"},
+			},
+		},
+	}
+	injected := []corev1.EnvVar{
+		{Name: "GROUP_NAME", Value: "test-rbg"},
+		{Name: "ROLE_NAME", Value: "prefill"},
+	}
+	preserved := []corev1.EnvVar{
+		{Name: "GROUP_NAME", Value: "test-rbg"},
+		{Name: "ROLE_NAME", Value: "user-defined"},
+	}
+
+	tests := []struct {
+		name      string
+		injection *workloadsv1alpha.InjectionPolicy
+		// env of the download, engine and exporter containers
+		want [][]corev1.EnvVar
+	}{
+		{
+			name: "keep the env defined by the user by default",
+			want: [][]corev1.EnvVar{nil, preserved, injected},
+		},
+		{
+			name:      "override the env defined by the user",
+			injection: &workloadsv1alpha.InjectionPolicy{EnvConflictPolicy: workloadsv1alpha.OverrideEnvConflictPolicy},
+			want:      [][]corev1.EnvVar{nil, injected, injected},
+		},
+		{
+			name:      "inject into the named containers only",
+			injection: &workloadsv1alpha.InjectionPolicy{Containers: []string{"download", "engine"}},
+			want:      [][]corev1.EnvVar{injected, preserved, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &workloadsv1alpha.RoleSpec{
+				Name:      "prefill",
+				Workload:  workloadsv1alpha.WorkloadSpec{APIVersion: "apps/v1", Kind: "Deployment"},
+				Injection: tt.injection,
+			}
+			got := podSpec.DeepCopy()
+			if err := NewDefaultInjector(nil, nil).InjectEnv(context.TODO(), got, rbg, role); err != nil {
+				t.Fatalf("InjectEnv() error = %v", err)
+			}
+			gotEnv := [][]corev1.EnvVar{got.Spec.InitContainers[0].Env, got.Spec.Containers[0].Env,
+				got.Spec.Containers[1].Env}
+			if !reflect.DeepEqual(gotEnv, tt.want) {
+				t.Errorf("InjectEnv() env = %v, want %v", gotEnv, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// SetConfigKey is the key of the aggregated discovery config in the ConfigMap of a RoleBasedGroupSet.
+const SetConfigKey = "config.yaml"
+
+// SetConfigMapName returns the name of the ConfigMap holding the aggregated discovery config of a RoleBasedGroupSet.
+// The names of the rbgs and roles cannot contain a dot, so the name never collides with the ConfigMaps of the rbgs.
+func SetConfigMapName(rbgsetName string) string {
+	return rbgsetName + ".discovery"
+}
+
+type SetConfig struct {
+	Version string      `json:"version"`
+	Set     SetInfo     `json:"set"`
+	Groups  []SetMember `json:"groups"`
+}
+
+type SetInfo struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// SetMember describes the roles and instances of a RoleBasedGroup of the set, in the same shape as the discovery
+// config of the group.
+type SetMember struct {
+	Name  string    `json:"name"`
+	Index int       `json:"index"`
+	Roles RolesInfo `json:"roles"`
+}
+
+// SetConfigBuilder builds the aggregated discovery config of a RoleBasedGroupSet from its RoleBasedGroups.
+type SetConfigBuilder struct {
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet
+	rbgs   []workloadsv1alpha1.RoleBasedGroup
+	// pods of the rbgs, the instances of the rbgs discovering pods are built from them
+	pods []corev1.Pod
+}
+
+func NewSetConfigBuilder(
+	rbgset *workloadsv1alpha1.RoleBasedGroupSet, rbgs []workloadsv1alpha1.RoleBasedGroup, pods []corev1.Pod,
+) *SetConfigBuilder {
+	return &SetConfigBuilder{rbgset: rbgset, rbgs: rbgs, pods: pods}
+}
+
+func (b *SetConfigBuilder) Build() ([]byte, error) {
+	podsOfRBG := make(map[string][]corev1.Pod)
+	for _, pod := range b.pods {
+		rbgName := pod.Labels[workloadsv1alpha1.SetNameLabelKey]
+		podsOfRBG[rbgName] = append(podsOfRBG[rbgName], pod)
+	}
+
+	members := make([]SetMember, 0, len(b.rbgs))
+	for i := range b.rbgs {
+		rbg := &b.rbgs[i]
+		index, _ := strconv.Atoi(rbg.Labels[workloadsv1alpha1.SetRBGIndexLabelKey])
+		builder := &ConfigBuilder{rbg: rbg, pods: podsOfRBG[rbg.Name]}
+		members = append(members, SetMember{
+			Name:  rbg.Name,
+			Index: index,
+			Roles: builder.buildRolesInfo(),
+		})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Index < members[j].Index
+	})
+
+	return yaml.Marshal(SetConfig{
+		Version: ConfigSchemaVersion,
+		Set: SetInfo{
+			Name: b.rbgset.Name,
+			Size: len(members),
+		},
+		Groups: members,
+	})
+}
@@ -0,0 +1,73 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestSetConfigBuilder_Build(t *testing.T) {
+	buildRBG := func(index string, discovery *workloadsv1alpha1.DiscoveryPolicy) workloadsv1alpha1.RoleBasedGroup {
+		return workloadsv1alpha1.RoleBasedGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-rbgset-" + index,
+				Labels: map[string]string{
+					workloadsv1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+					workloadsv1alpha1.SetRBGIndexLabelKey:   index,
+				},
+			},
+			Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+				Roles: []workloadsv1alpha1.RoleSpec{{
+					Name:         "prefill",
+					Replicas:     ptr.To(int32(1)),
+					Workload:     workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"},
+					ServicePorts: []corev1.ServicePort{{Name: "http", Port: 8000}},
+				}},
+				Discovery: discovery,
+			},
+		}
+	}
+	rbgset := &workloadsv1alpha1.RoleBasedGroupSet{ObjectMeta: metav1.ObjectMeta{Name: "test-rbgset"}}
+	rbgs := []workloadsv1alpha1.RoleBasedGroup{
+		buildRBG("1", &workloadsv1alpha1.DiscoveryPolicy{Source: workloadsv1alpha1.PodsDiscoverySource}),
+		buildRBG("0", nil),
+	}
+	pods := []corev1.Pod{
+		buildDiscoveryPod("test-rbgset-1-prefill-0", "prefill", "10.0.0.1", true, nil),
+	}
+	pods[0].Labels[workloadsv1alpha1.SetNameLabelKey] = "test-rbgset-1"
+
+	data, err := NewSetConfigBuilder(rbgset, rbgs, pods).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `groups:
+- index: 0
+  name: test-rbgset-0
+  roles:
+    prefill:
+      instances:
+      - address: prefill-0.test-rbgset-0-prefill
+        ports:
+          http: 8000
+      size: 1
+- index: 1
+  name: test-rbgset-1
+  roles:
+    prefill:
+      instances:
+      - address: 10.0.0.1
+        ip: 10.0.0.1
+        name: test-rbgset-1-prefill-0
+        ports:
+          http: 8000
+        ready: true
+      size: 1
+set:
+  name: test-rbgset
+  size: 2
+version: v1
+`, string(data))
+}
@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// StartupBarrierContainerName is the name of the init container blocking the pods of a role until its dependencies
+// are ready.
+const StartupBarrierContainerName = "rbg-startup-barrier"
+
+// InjectStartupBarrier prepends the startup barrier init container to podSpec if role has a startup barrier and
+// dependencies on the readiness of roles. The init container runs rbg-agent, which waits until the dependencies are
+// ready in the status of rbg.
+func (i *DefaultInjector) InjectStartupBarrier(
+	_ context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec,
+) error {
+	barrier := role.StartupBarrier
+	waitFor := barrierRoles(role)
+	if barrier == nil || len(waitFor) == 0 {
+		return nil
+	}
+
+	args := []string{
+		"--wait-for-roles=" + strings.Join(waitFor, ","),
+		"--group=" + rbg.Name,
+	}
+	if barrier.TimeoutSeconds != nil {
+		args = append(args, fmt.Sprintf("--wait-timeout=%ds", *barrier.TimeoutSeconds))
+	}
+	container := corev1.Container{
+		Name:    StartupBarrierContainerName,
+		Image:   barrier.Image,
+		Command: []string{"/rbg-agent"},
+		Args:    args,
+	}
+
+	initContainers := []corev1.Container{container}
+	for _, c := range podSpec.Spec.InitContainers {
+		if c.Name != StartupBarrierContainerName {
+			initContainers = append(initContainers, c)
+		}
+	}
+	podSpec.Spec.InitContainers = initContainers
+	return nil
+}
+
+// barrierRoles returns the roles the startup barrier of role waits for, as <role> or <role>=<threshold>. The other
+// dependencies are only checked by the controller: created roles and external objects are not visible to the
+// barrier in the status of the RoleBasedGroup.
+func barrierRoles(role *workloadsv1alpha1.RoleSpec) []string {
+	roles := slices.Clone(role.Dependencies)
+	for _, dep := range role.DependsOn {
+		if dep.Role == "" || dep.GetCondition() != workloadsv1alpha1.ReadyDependencyCondition ||
+			slices.Contains(role.Dependencies, dep.Role) {
+			continue
+		}
+		if dep.Threshold != nil {
+			roles = append(roles, dep.Role+"="+dep.Threshold.String())
+		} else {
+			roles = append(roles, dep.Role)
+		}
+	}
+	return roles
+}
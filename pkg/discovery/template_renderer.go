@@ -0,0 +1,83 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// TemplateData is the data the templates in the env values and args of a role are rendered with. It extends the
+// discovery config with the role of the pods. The rendered values are part of the pod template, so a value
+// depending on the size or the instances of a role changes when the role is scaled, which rolls out the pods.
+type TemplateData struct {
+	ClusterConfig
+	Role RoleInfo
+}
+
+type RoleInfo struct {
+	Name string
+	Size int
+
+	// indexed is set if the container being rendered has the env var ROLE_INDEX
+	indexed bool
+}
+
+// Index renders the index of the pod within the role. The pod template is shared by all pods of the role, so it
+// renders a reference to the env var ROLE_INDEX which is expanded by the kubelet.
+func (r RoleInfo) Index() (string, error) {
+	if !r.indexed {
+		return "", fmt.Errorf("index of role %s requires the env var ROLE_INDEX, which is only injected into "+
+			"StatefulSet and LeaderWorkerSet roles with injection.env", r.Name)
+	}
+	return "$(ROLE_INDEX)", nil
+}
+
+func (i *DefaultInjector) RenderTemplates(
+	ctx context.Context, podSpec *corev1.PodTemplateSpec, rbg *workloadsv1alpha1.RoleBasedGroup,
+	role *workloadsv1alpha1.RoleSpec,
+) error {
+	// Build the instances from the spec rather than the observed pods, otherwise the pods are rolled out whenever
+	// a pod of another role is recreated.
+	builder := &ConfigBuilder{rbg: rbg, role: role, static: true}
+	data := TemplateData{
+		ClusterConfig: builder.buildClusterConfig(),
+		Role:          RoleInfo{Name: role.Name, Size: int(*role.Replicas)},
+	}
+	render := func(text string) (string, error) {
+		// Leave the strings without templates untouched
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+		rendered, err := renderTemplate(text, data)
+		return string(rendered), err
+	}
+
+	for _, container := range injectionTargets(podSpec, role) {
+		// the env vars are injected before the templates are rendered
+		data.Role.indexed = role.InjectEnvEnabled() && slices.ContainsFunc(container.Env, func(env corev1.EnvVar) bool {
+			return env.Name == "ROLE_INDEX"
+		})
+		for idx, arg := range container.Args {
+			rendered, err := render(arg)
+			if err != nil {
+				return fmt.Errorf("failed to render args of container %s: %w", container.Name, err)
+			}
+			container.Args[idx] = rendered
+		}
+		for idx, env := range container.Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+			rendered, err := render(env.Value)
+			if err != nil {
+				return fmt.Errorf("failed to render env %s of container %s: %w", env.Name, container.Name, err)
+			}
+			container.Env[idx].Value = rendered
+		}
+	}
+	return nil
+}
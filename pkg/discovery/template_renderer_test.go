@@ -0,0 +1,184 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	sts := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	deploy := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "Deployment"}
+	ports := []corev1.ServicePort{{Name: "http", Port: 8000}}
+	roleIndex := corev1.EnvVar{Name: "ROLE_INDEX", ValueFrom: &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels['apps.kubernetes.io/pod-index']"},
+	}}
+
+	tests := []struct {
+		name       string
+		workload   workloadsv1alpha1.WorkloadSpec
+		containers []string
+		noEnv      bool
+		args       []string
+		env        []corev1.EnvVar
+		expectArgs []string
+		expectEnv  []corev1.EnvVar
+		expectErr  bool
+	}{
+		{
+			name:     "Render args and env",
+			workload: sts,
+			args: []string{
+				"--prefill-addrs={{ join (addresses .Roles.prefill.Instances) \",\" }}",
+				"--node-rank={{ .Role.Index }}",
+				"--chat-template=/templates/chat.jinja",
+			},
+			env: []corev1.EnvVar{
+				{Name: "MASTER_ADDR", Value: "{{ (index .Roles.prefill.Instances 0).Address }}"},
+				{Name: "GROUP", Value: "{{ .Group.Name }}-{{ .Role.Name }}"},
+				{Name: "POD_IP", ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"},
+				}},
+				roleIndex,
+			},
+			expectArgs: []string{
+				"--prefill-addrs=prefill-0.test-rbg-prefill,prefill-1.test-rbg-prefill",
+				"--node-rank=$(ROLE_INDEX)",
+				"--chat-template=/templates/chat.jinja",
+			},
+			expectEnv: []corev1.EnvVar{
+				{Name: "MASTER_ADDR", Value: "prefill-0.test-rbg-prefill"},
+				{Name: "GROUP", Value: "test-rbg-decode"},
+				{Name: "POD_IP", ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"},
+				}},
+				roleIndex,
+			},
+		},
+		{
+			name:       "Render the named containers only",
+			workload:   sts,
+			containers: []string{"sidecar"},
+			args:       []string{"--prefill-addrs={{ addresses .Roles.prefill.Instances }}"},
+			expectArgs: []string{"--prefill-addrs={{ addresses .Roles.prefill.Instances }}"},
+		},
+		{
+			name:      "Index of a Deployment role",
+			workload:  deploy,
+			args:      []string{"--node-rank={{ .Role.Index }}"},
+			expectErr: true,
+		},
+		{
+			name:      "Index without the env var ROLE_INDEX in the container",
+			workload:  sts,
+			args:      []string{"--node-rank={{ .Role.Index }}"},
+			expectErr: true,
+		},
+		{
+			name:      "Index with the env injection disabled",
+			workload:  sts,
+			noEnv:     true,
+			args:      []string{"--node-rank={{ .Role.Index }}"},
+			env:       []corev1.EnvVar{roleIndex},
+			expectErr: true,
+		},
+		{
+			name:       "Size of the roles",
+			workload:   sts,
+			args:       []string{"--prefill={{ .Roles.prefill.Size }}", "--decode={{ .Role.Size }}"},
+			expectArgs: []string{"--prefill=2", "--decode=1"},
+		},
+		{
+			name:      "Missing role",
+			workload:  sts,
+			args:      []string{"--decode-addrs={{ .Roles.decoder.Instances }}"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decode := workloadsv1alpha1.RoleSpec{
+				Name:         "decode",
+				Replicas:     ptr.To(int32(1)),
+				Workload:     tt.workload,
+				ServicePorts: ports,
+				Injection: &workloadsv1alpha1.InjectionPolicy{
+					RenderTemplates: true,
+					Containers:      tt.containers,
+				},
+			}
+			if tt.noEnv {
+				decode.Injection.Env = ptr.To(false)
+			}
+			rbg := &workloadsv1alpha1.RoleBasedGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+				Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+					Roles: []workloadsv1alpha1.RoleSpec{
+						{Name: "prefill", Replicas: ptr.To(int32(2)), Workload: sts, ServicePorts: ports},
+						decode,
+					},
+					// the templates are rendered from the spec even if the rbg discovers pods
+					Discovery: &workloadsv1alpha1.DiscoveryPolicy{Source: workloadsv1alpha1.PodsDiscoverySource},
+				},
+			}
+			podSpec := &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "engine", Args: tt.args, Env: tt.env}},
+				},
+			}
+
+			err := NewDefaultInjector(nil, nil).RenderTemplates(context.TODO(), podSpec, rbg, &decode)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectArgs, podSpec.Spec.Containers[0].Args)
+			assert.Equal(t, tt.expectEnv, podSpec.Spec.Containers[0].Env)
+		})
+	}
+}
+
+func TestRenderTemplates_Scaling(t *testing.T) {
+	sts := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	decode := workloadsv1alpha1.RoleSpec{
+		Name:      "decode",
+		Replicas:  ptr.To(int32(1)),
+		Workload:  sts,
+		Injection: &workloadsv1alpha1.InjectionPolicy{RenderTemplates: true},
+	}
+	render := func(prefillReplicas int32) []string {
+		rbg := &workloadsv1alpha1.RoleBasedGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+			Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+				Roles: []workloadsv1alpha1.RoleSpec{
+					{Name: "prefill", Replicas: ptr.To(prefillReplicas), Workload: sts},
+					decode,
+				},
+			},
+		}
+		podSpec := &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "engine", Args: []string{
+					"--prefill-size={{ .Roles.prefill.Size }}",
+					"--prefill-instances={{ len .Roles.prefill.Instances }}",
+					"--master={{ (index .Roles.prefill.Instances 0).Address }}",
+				}}},
+			},
+		}
+		assert.NoError(t, NewDefaultInjector(nil, nil).RenderTemplates(context.TODO(), podSpec, rbg, &decode))
+		return podSpec.Spec.Containers[0].Args
+	}
+
+	// the values depending on the size or the instances of prefill change with its replicas, the others do not
+	assert.Equal(t, []string{"--prefill-size=2", "--prefill-instances=2", "--master=prefill-0.test-rbg-prefill"},
+		render(2))
+	assert.Equal(t, []string{"--prefill-size=3", "--prefill-instances=3", "--master=prefill-0.test-rbg-prefill"},
+		render(3))
+}
@@ -150,13 +150,19 @@ func (r *DeploymentReconciler) ConstructRoleStatus(
 
 	currentReplicas := *deploy.Spec.Replicas
 	currentReady := deploy.Status.ReadyReplicas
+	// the status of the deployment is stale until the deployment controller observes its latest generation
+	var currentUpdated int32
+	if deploy.Status.ObservedGeneration == deploy.Generation {
+		currentUpdated = deploy.Status.UpdatedReplicas
+	}
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady ||
+		status.UpdatedReplicas != currentUpdated {
+		// keep the restart records of the role
+		status.Name = role.Name
+		status.Replicas = currentReplicas
+		status.ReadyReplicas = currentReady
+		status.UpdatedReplicas = currentUpdated
 		updateStatus = true
 	}
 
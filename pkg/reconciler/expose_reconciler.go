@@ -0,0 +1,351 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+var (
+	HTTPRouteGVK     = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	InferencePoolGVK = schema.GroupVersionKind{
+		Group: "inference.networking.x-k8s.io", Version: "v1alpha2", Kind: "InferencePool",
+	}
+)
+
+const defaultEndpointPickerPort = 9002
+
+// ExposeReconciler reconciles the Gateway API HTTPRoute and the Inference Extension InferencePool defined by
+// rbg.spec.expose. The objects are handled as unstructured so that the CRDs are optional.
+type ExposeReconciler struct {
+	client client.Client
+}
+
+func NewExposeReconciler(client client.Client) *ExposeReconciler {
+	return &ExposeReconciler{client: client}
+}
+
+// Reconcile creates or updates the objects of rbg.spec.expose and deletes the ones no longer desired. The objects
+// are created once all roles of rbg are ready, and are kept afterwards. It returns the Exposed condition of rbg, or
+// nil if rbg is not exposed.
+func (r *ExposeReconciler) Reconcile(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, ready bool,
+) (*metav1.Condition, error) {
+	expose := rbg.Spec.Expose
+	if expose == nil {
+		// the Exposed condition is kept until the objects are deleted, so that the rbgs which never exposed
+		// anything do not get the objects from the API server on every reconcile
+		if meta.FindStatusCondition(rbg.Status.Conditions, string(workloadsv1alpha1.RoleBasedGroupExposed)) == nil {
+			return nil, nil
+		}
+		return nil, utilerrors.NewAggregate([]error{
+			r.deleteObject(ctx, rbg, HTTPRouteGVK),
+			r.deleteObject(ctx, rbg, InferencePoolGVK),
+		})
+	}
+
+	role, err := rbg.GetRole(expose.Role)
+	if err != nil {
+		return exposedCondition(metav1.ConditionFalse, "RoleNotFound", err.Error()), nil
+	}
+	port, err := exposedPort(expose, role)
+	if err != nil {
+		return exposedCondition(metav1.ConditionFalse, "PortNotFound", err.Error()), nil
+	}
+
+	var desired []*unstructured.Unstructured
+	var route *unstructured.Unstructured
+	if expose.InferencePool != nil {
+		desired = append(desired, constructInferencePool(rbg, role, port))
+	} else if err := r.deleteObject(ctx, rbg, InferencePoolGVK); err != nil {
+		return nil, err
+	}
+	if expose.HTTPRoute != nil {
+		route = constructHTTPRoute(rbg, role, port)
+		desired = append(desired, route)
+	} else if err := r.deleteObject(ctx, rbg, HTTPRouteGVK); err != nil {
+		return nil, err
+	}
+	if len(desired) == 0 {
+		return exposedCondition(metav1.ConditionFalse, "NothingToExpose",
+			"Neither httpRoute nor inferencePool is set"), nil
+	}
+
+	if !ready {
+		published, err := r.objectExists(ctx, desired[0])
+		if err != nil && !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+		if !published {
+			return exposedCondition(metav1.ConditionFalse, "WaitingForReady",
+				"The objects are created once all roles are ready"), nil
+		}
+	}
+
+	for _, obj := range desired {
+		current, err := r.createOrUpdate(ctx, obj)
+		if meta.IsNoMatchError(err) {
+			return exposedCondition(metav1.ConditionFalse, "CRDNotInstalled",
+				fmt.Sprintf("%s is not installed in the cluster", obj.GetKind())), nil
+		} else if err != nil {
+			return nil, err
+		}
+		if obj == route {
+			route = current
+		}
+	}
+
+	if route == nil {
+		return exposedCondition(metav1.ConditionTrue, "InferencePoolCreated",
+			fmt.Sprintf("InferencePool %s is created", rbg.Name)), nil
+	}
+	return httpRouteCondition(route), nil
+}
+
+func (r *ExposeReconciler) objectExists(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, current)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *ExposeReconciler) createOrUpdate(
+	ctx context.Context, obj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	logger := log.FromContext(ctx)
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.client.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, current)
+	if apierrors.IsNotFound(err) {
+		logger.Info("create expose object", "kind", obj.GetKind(), "name", obj.GetName())
+		return obj, r.client.Create(ctx, obj)
+	} else if err != nil {
+		return nil, err
+	}
+
+	labels := current.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, obj.GetLabels())
+	if equality.Semantic.DeepEqual(current.Object["spec"], obj.Object["spec"]) &&
+		equality.Semantic.DeepEqual(current.GetLabels(), labels) &&
+		equality.Semantic.DeepEqual(current.GetOwnerReferences(), obj.GetOwnerReferences()) {
+		return current, nil
+	}
+
+	logger.Info("update expose object", "kind", obj.GetKind(), "name", obj.GetName())
+	current.Object["spec"] = obj.Object["spec"]
+	current.SetLabels(labels)
+	current.SetOwnerReferences(obj.GetOwnerReferences())
+	return current, r.client.Update(ctx, current)
+}
+
+// deleteObject deletes the object of kind gvk named after rbg if it is controlled by rbg.
+func (r *ExposeReconciler) deleteObject(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, gvk schema.GroupVersionKind,
+) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, obj)
+	// nothing to delete if the CRD is not installed
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(obj, rbg) {
+		return nil
+	}
+	log.FromContext(ctx).Info("delete expose object", "kind", gvk.Kind, "name", obj.GetName())
+	return client.IgnoreNotFound(r.client.Delete(ctx, obj))
+}
+
+func exposedPort(expose *workloadsv1alpha1.ExposeSpec, role *workloadsv1alpha1.RoleSpec) (int64, error) {
+	if expose.Port != nil {
+		return int64(*expose.Port), nil
+	}
+	if len(role.ServicePorts) == 0 {
+		return 0, fmt.Errorf("role %s has no servicePorts, expose.port is required", role.Name)
+	}
+	return int64(role.ServicePorts[0].Port), nil
+}
+
+func newExposeObject(
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, gvk schema.GroupVersionKind,
+	spec map[string]interface{},
+) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(rbg.Name)
+	obj.SetNamespace(rbg.Namespace)
+	obj.SetLabels(rbg.GetCommonLabelsFromRole(role))
+	obj.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+	})
+	return obj
+}
+
+// constructHTTPRoute returns the HTTPRoute of rbg. The fields defaulted by the CRD are set explicitly, so that the
+// desired spec is comparable with the existing one.
+func constructHTTPRoute(
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, port int64,
+) *unstructured.Unstructured {
+	httpRoute := rbg.Spec.Expose.HTTPRoute
+
+	parentRefs := make([]interface{}, 0, len(httpRoute.ParentRefs))
+	for _, ref := range httpRoute.ParentRefs {
+		parentRef := map[string]interface{}{
+			"group": "gateway.networking.k8s.io",
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	backendRef := map[string]interface{}{
+		"group":  "",
+		"kind":   "Service",
+		"name":   rbg.GetServiceName(role),
+		"port":   port,
+		"weight": int64(1),
+	}
+	if rbg.Spec.Expose.InferencePool != nil {
+		backendRef = map[string]interface{}{
+			"group":  InferencePoolGVK.Group,
+			"kind":   InferencePoolGVK.Kind,
+			"name":   rbg.Name,
+			"weight": int64(1),
+		}
+	}
+
+	pathPrefix := httpRoute.PathPrefix
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+	spec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{"type": "PathPrefix", "value": pathPrefix},
+					},
+				},
+				"backendRefs": []interface{}{backendRef},
+			},
+		},
+	}
+	if len(httpRoute.Hostnames) > 0 {
+		hostnames := make([]interface{}, 0, len(httpRoute.Hostnames))
+		for _, hostname := range httpRoute.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+	return newExposeObject(rbg, role, HTTPRouteGVK, spec)
+}
+
+// constructInferencePool returns the InferencePool of rbg selecting the pods of role.
+func constructInferencePool(
+	rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec, port int64,
+) *unstructured.Unstructured {
+	pool := rbg.Spec.Expose.InferencePool
+
+	selector := map[string]interface{}{}
+	for k, v := range rbg.GetCommonLabelsFromRole(role) {
+		selector[k] = v
+	}
+	eppPort := int64(defaultEndpointPickerPort)
+	if pool.EndpointPickerPort != nil {
+		eppPort = int64(*pool.EndpointPickerPort)
+	}
+	failureMode := "FailClose"
+	if pool.FailOpen {
+		failureMode = "FailOpen"
+	}
+	spec := map[string]interface{}{
+		"selector":         selector,
+		"targetPortNumber": port,
+		"extensionRef": map[string]interface{}{
+			"group":       "",
+			"kind":        "Service",
+			"name":        pool.EndpointPicker,
+			"portNumber":  eppPort,
+			"failureMode": failureMode,
+		},
+	}
+	return newExposeObject(rbg, role, InferencePoolGVK, spec)
+}
+
+// httpRouteCondition returns the Exposed condition from the status of the Gateways the route is attached to.
+func httpRouteCondition(route *unstructured.Unstructured) *metav1.Condition {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) == 0 {
+		return exposedCondition(metav1.ConditionFalse, "RoutePending",
+			fmt.Sprintf("HTTPRoute %s is not reported by any Gateway yet", route.GetName()))
+	}
+
+	var failures []string
+	for _, parent := range parents {
+		parentMap, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gateway, _, _ := unstructured.NestedString(parentMap, "parentRef", "name")
+		conditions, _, _ := unstructured.NestedSlice(parentMap, "conditions")
+		for _, condType := range []string{"Accepted", "ResolvedRefs"} {
+			status, message := "Unknown", ""
+			for _, cond := range conditions {
+				condMap, ok := cond.(map[string]interface{})
+				if !ok || condMap["type"] != condType {
+					continue
+				}
+				status, _ = condMap["status"].(string)
+				message, _ = condMap["message"].(string)
+			}
+			if status != string(metav1.ConditionTrue) {
+				failures = append(failures, fmt.Sprintf("gateway %s: %s=%s %s", gateway, condType, status, message))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return exposedCondition(metav1.ConditionFalse, "RouteNotAccepted", strings.Join(failures, "; "))
+	}
+	return exposedCondition(metav1.ConditionTrue, "RouteAccepted",
+		fmt.Sprintf("HTTPRoute %s is accepted by all Gateways", route.GetName()))
+}
+
+func exposedCondition(status metav1.ConditionStatus, reason, message string) *metav1.Condition {
+	return &metav1.Condition{
+		Type:               string(workloadsv1alpha1.RoleBasedGroupExposed),
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
@@ -0,0 +1,218 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func exposeTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	for _, gvk := range []schema.GroupVersionKind{HTTPRouteGVK, InferencePoolGVK} {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+	return scheme
+}
+
+func buildExposedRBG(expose *workloadsv1alpha1.ExposeSpec) *workloadsv1alpha1.RoleBasedGroup {
+	return &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default", UID: "rbg-uid"},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{
+				{
+					Name:         "router",
+					ServicePorts: []corev1.ServicePort{{Name: "http", Port: 8000}},
+					Service:      &workloadsv1alpha1.RoleService{Type: workloadsv1alpha1.ClusterIPServiceType},
+				},
+			},
+			Expose: expose,
+		},
+	}
+}
+
+func getExposeObject(
+	t *testing.T, c client.Client, gvk schema.GroupVersionKind,
+) (*unstructured.Unstructured, error) {
+	t.Helper()
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := c.Get(context.TODO(), types.NamespacedName{Name: "test-rbg", Namespace: "default"}, obj)
+	return obj, err
+}
+
+func TestExposeReconciler_Reconcile(t *testing.T) {
+	httpRoute := &workloadsv1alpha1.ExposeHTTPRoute{
+		ParentRefs: []workloadsv1alpha1.ExposeParentReference{{Name: "inference-gateway"}},
+		Hostnames:  []string{"llm.example.com"},
+	}
+
+	t.Run("Wait until the rbg is ready", func(t *testing.T) {
+		rbg := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{Role: "router", HTTPRoute: httpRoute})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(rbg).Build()
+
+		cond, err := NewExposeReconciler(c).Reconcile(context.TODO(), rbg, false)
+		assert.NoError(t, err)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "WaitingForReady", cond.Reason)
+		_, err = getExposeObject(t, c, HTTPRouteGVK)
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("Route to the service of the role", func(t *testing.T) {
+		rbg := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{Role: "router", HTTPRoute: httpRoute})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(rbg).Build()
+
+		cond, err := NewExposeReconciler(c).Reconcile(context.TODO(), rbg, true)
+		assert.NoError(t, err)
+		assert.Equal(t, "RoutePending", cond.Reason)
+
+		route, err := getExposeObject(t, c, HTTPRouteGVK)
+		assert.NoError(t, err)
+		assert.True(t, metav1.IsControlledBy(route, rbg))
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		assert.Equal(t, []string{"llm.example.com"}, hostnames)
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+		backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+		backendRef := backendRefs[0].(map[string]interface{})
+		assert.Equal(t, "test-rbg-router-svc", backendRef["name"])
+		assert.Equal(t, "Service", backendRef["kind"])
+		assert.EqualValues(t, 8000, backendRef["port"])
+
+		// once published, the route is kept when the rbg is not ready
+		cond, err = NewExposeReconciler(c).Reconcile(context.TODO(), rbg, false)
+		assert.NoError(t, err)
+		assert.Equal(t, "RoutePending", cond.Reason)
+	})
+
+	t.Run("Route to the inference pool", func(t *testing.T) {
+		rbg := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{
+			Role:          "router",
+			HTTPRoute:     httpRoute,
+			InferencePool: &workloadsv1alpha1.ExposeInferencePool{EndpointPicker: "router-epp", FailOpen: true},
+		})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(rbg).Build()
+
+		_, err := NewExposeReconciler(c).Reconcile(context.TODO(), rbg, true)
+		assert.NoError(t, err)
+
+		pool, err := getExposeObject(t, c, InferencePoolGVK)
+		assert.NoError(t, err)
+		selector, _, _ := unstructured.NestedStringMap(pool.Object, "spec", "selector")
+		assert.Equal(t, map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+			workloadsv1alpha1.SetRoleLabelKey: "router",
+		}, selector)
+		targetPort, _, _ := unstructured.NestedInt64(pool.Object, "spec", "targetPortNumber")
+		assert.Equal(t, int64(8000), targetPort)
+		failureMode, _, _ := unstructured.NestedString(pool.Object, "spec", "extensionRef", "failureMode")
+		assert.Equal(t, "FailOpen", failureMode)
+
+		route, err := getExposeObject(t, c, HTTPRouteGVK)
+		assert.NoError(t, err)
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+		backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+		assert.Equal(t, "InferencePool", backendRefs[0].(map[string]interface{})["kind"])
+	})
+
+	t.Run("Delete the objects when expose is removed", func(t *testing.T) {
+		exposed := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{Role: "router", HTTPRoute: httpRoute})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(exposed).Build()
+		cond, err := NewExposeReconciler(c).Reconcile(context.TODO(), exposed, true)
+		assert.NoError(t, err)
+
+		unexposed := buildExposedRBG(nil)
+		unexposed.Status.Conditions = []metav1.Condition{*cond}
+		cond, err = NewExposeReconciler(c).Reconcile(context.TODO(), unexposed, true)
+		assert.NoError(t, err)
+		assert.Nil(t, cond)
+		_, err = getExposeObject(t, c, HTTPRouteGVK)
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("Skip the cleanup when the rbg was never exposed", func(t *testing.T) {
+		exposed := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{Role: "router", HTTPRoute: httpRoute})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(exposed).Build()
+		_, err := NewExposeReconciler(c).Reconcile(context.TODO(), exposed, true)
+		assert.NoError(t, err)
+
+		cond, err := NewExposeReconciler(c).Reconcile(context.TODO(), buildExposedRBG(nil), true)
+		assert.NoError(t, err)
+		assert.Nil(t, cond)
+		_, err = getExposeObject(t, c, HTTPRouteGVK)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown role", func(t *testing.T) {
+		rbg := buildExposedRBG(&workloadsv1alpha1.ExposeSpec{Role: "decode", HTTPRoute: httpRoute})
+		c := fake.NewClientBuilder().WithScheme(exposeTestScheme()).WithObjects(rbg).Build()
+
+		cond, err := NewExposeReconciler(c).Reconcile(context.TODO(), rbg, true)
+		assert.NoError(t, err)
+		assert.Equal(t, "RoleNotFound", cond.Reason)
+	})
+}
+
+func TestHTTPRouteCondition(t *testing.T) {
+	parentStatus := func(accepted, resolved string) interface{} {
+		return map[string]interface{}{
+			"parentRef": map[string]interface{}{"name": "inference-gateway"},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": accepted},
+				map[string]interface{}{"type": "ResolvedRefs", "status": resolved, "message": "backend not found"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		parents      []interface{}
+		expectStatus metav1.ConditionStatus
+		expectReason string
+	}{
+		{
+			name:         "No parent status",
+			expectStatus: metav1.ConditionFalse,
+			expectReason: "RoutePending",
+		},
+		{
+			name:         "Accepted",
+			parents:      []interface{}{parentStatus("True", "True")},
+			expectStatus: metav1.ConditionTrue,
+			expectReason: "RouteAccepted",
+		},
+		{
+			name:         "Unresolved backend",
+			parents:      []interface{}{parentStatus("True", "False")},
+			expectStatus: metav1.ConditionFalse,
+			expectReason: "RouteNotAccepted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			route.SetName("test-rbg")
+			if tt.parents != nil {
+				_ = unstructured.SetNestedSlice(route.Object, tt.parents, "status", "parents")
+			}
+			cond := httpRouteCondition(route)
+			assert.Equal(t, tt.expectStatus, cond.Status)
+			assert.Equal(t, tt.expectReason, cond.Reason)
+		})
+	}
+}
@@ -93,13 +93,15 @@ func (r *LeaderWorkerSetReconciler) ConstructRoleStatus(
 
 	currentReplicas := lws.Status.Replicas
 	currentReady := lws.Status.ReadyReplicas
+	currentUpdated := lws.Status.UpdatedReplicas
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady ||
+		status.UpdatedReplicas != currentUpdated {
+		// keep the restart records of the role
+		status.Name = role.Name
+		status.Replicas = currentReplicas
+		status.ReadyReplicas = currentReady
+		status.UpdatedReplicas = currentUpdated
 		updateStatus = true
 	}
 
@@ -194,7 +196,7 @@ func (r *LeaderWorkerSetReconciler) constructLWSApplyConfiguration(
 	}
 	workerPodReconciler := NewPodReconciler(r.scheme, r.client)
 	// workerTemplate do not need to inject sidecar
-	workerPodReconciler.SetInjectors([]string{"config", "env"})
+	workerPodReconciler.SetInjectors([]string{"config", "env", "template", "barrier"})
 	workerTemplateApplyCfg, err := workerPodReconciler.ConstructPodTemplateSpecApplyConfiguration(
 		ctx, rbg, role, rbg.GetCommonLabelsFromRole(role), workerTemp,
 	)
@@ -0,0 +1,160 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// PodDisruptionBudgetReconciler reconciles the PodDisruptionBudgets of the roles and of the whole group.
+type PodDisruptionBudgetReconciler struct {
+	client client.Client
+}
+
+func NewPodDisruptionBudgetReconciler(client client.Client) *PodDisruptionBudgetReconciler {
+	return &PodDisruptionBudgetReconciler{client: client}
+}
+
+// Reconcile creates or updates the PodDisruptionBudget of the group if it has a disruption budget, or else one for
+// every role with a disruption budget, and deletes the PodDisruptionBudgets of rbg which are no longer desired.
+func (r *PodDisruptionBudgetReconciler) Reconcile(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	desired := constructPodDisruptionBudgets(rbg)
+
+	errs := make([]error, 0)
+	desiredNames := make(map[string]struct{}, len(desired))
+	for _, pdb := range desired {
+		desiredNames[pdb.Name] = struct{}{}
+		if err := r.createOrUpdate(ctx, pdb); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := r.client.List(ctx, pdbList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name},
+	); err != nil {
+		errs = append(errs, err)
+		return utilerrors.NewAggregate(errs)
+	}
+	for i := range pdbList.Items {
+		pdb := &pdbList.Items[i]
+		if _, ok := desiredNames[pdb.Name]; ok || !metav1.IsControlledBy(pdb, rbg) {
+			continue
+		}
+		log.FromContext(ctx).Info("delete pdb", "pdb", pdb.Name)
+		if err := r.client.Delete(ctx, pdb); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (r *PodDisruptionBudgetReconciler) createOrUpdate(ctx context.Context, pdb *policyv1.PodDisruptionBudget) error {
+	logger := log.FromContext(ctx)
+
+	oldPdb := &policyv1.PodDisruptionBudget{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, oldPdb)
+	if apierrors.IsNotFound(err) {
+		logger.Info("create pdb", "pdb", pdb.Name)
+		return r.client.Create(ctx, pdb)
+	} else if err != nil {
+		return err
+	}
+
+	if equal, err := semanticallyEqualPodDisruptionBudget(oldPdb, pdb); equal {
+		logger.V(1).Info("pdb equal, skip reconcile", "pdb", pdb.Name)
+		return nil
+	} else {
+		logger.V(1).Info(fmt.Sprintf("pdb not equal, diff: %s", err.Error()), "pdb", pdb.Name)
+	}
+
+	newPdb := oldPdb.DeepCopy()
+	newPdb.Labels = pdb.Labels
+	newPdb.OwnerReferences = pdb.OwnerReferences
+	newPdb.Spec.Selector = pdb.Spec.Selector
+	newPdb.Spec.MinAvailable = pdb.Spec.MinAvailable
+	newPdb.Spec.MaxUnavailable = pdb.Spec.MaxUnavailable
+	return r.client.Update(ctx, newPdb)
+}
+
+// constructPodDisruptionBudgets returns the desired PodDisruptionBudgets of rbg. The PodDisruptionBudget of a role
+// has the name of its workload and selects the pods by the common labels of the role, the one of the group has
+// the name of rbg and selects the pods of all roles. The eviction API refuses to evict a pod selected by more than
+// one PodDisruptionBudget, so the budgets of the roles are ignored while the group has a budget.
+func constructPodDisruptionBudgets(rbg *workloadsv1alpha1.RoleBasedGroup) []*policyv1.PodDisruptionBudget {
+	if rbg.Spec.DisruptionBudget != nil {
+		labels := map[string]string{workloadsv1alpha1.SetNameLabelKey: rbg.Name}
+		return []*policyv1.PodDisruptionBudget{
+			newPodDisruptionBudget(rbg, rbg.Name, labels, rbg.Spec.DisruptionBudget),
+		}
+	}
+
+	var pdbs []*policyv1.PodDisruptionBudget
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		if role.DisruptionBudget == nil {
+			continue
+		}
+		labels := rbg.GetCommonLabelsFromRole(role)
+		pdbs = append(pdbs, newPodDisruptionBudget(rbg, rbg.GetWorkloadName(role), labels, role.DisruptionBudget))
+	}
+	return pdbs
+}
+
+func newPodDisruptionBudget(
+	rbg *workloadsv1alpha1.RoleBasedGroup, name string, labels map[string]string,
+	budget *workloadsv1alpha1.DisruptionBudget,
+) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rbg.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: labels},
+			MinAvailable:   budget.MinAvailable,
+			MaxUnavailable: budget.MaxUnavailable,
+		},
+	}
+	if pdb.Spec.MinAvailable == nil && pdb.Spec.MaxUnavailable == nil {
+		maxUnavailable := intstr.FromInt32(1)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	return pdb
+}
+
+func semanticallyEqualPodDisruptionBudget(oldPdb, newPdb *policyv1.PodDisruptionBudget) (bool, error) {
+	if !reflect.DeepEqual(oldPdb.Labels, newPdb.Labels) {
+		return false, fmt.Errorf("labels not equal, old: %v, new: %v", oldPdb.Labels, newPdb.Labels)
+	}
+	if !reflect.DeepEqual(oldPdb.OwnerReferences, newPdb.OwnerReferences) {
+		return false, fmt.Errorf("ownerReferences not equal, old: %v, new: %v",
+			oldPdb.OwnerReferences, newPdb.OwnerReferences)
+	}
+	if !reflect.DeepEqual(oldPdb.Spec.Selector, newPdb.Spec.Selector) {
+		return false, fmt.Errorf("selector not equal, old: %v, new: %v", oldPdb.Spec.Selector, newPdb.Spec.Selector)
+	}
+	if !reflect.DeepEqual(oldPdb.Spec.MinAvailable, newPdb.Spec.MinAvailable) {
+		return false, fmt.Errorf("minAvailable not equal, old: %v, new: %v",
+			oldPdb.Spec.MinAvailable, newPdb.Spec.MinAvailable)
+	}
+	if !reflect.DeepEqual(oldPdb.Spec.MaxUnavailable, newPdb.Spec.MaxUnavailable) {
+		return false, fmt.Errorf("maxUnavailable not equal, old: %v, new: %v",
+			oldPdb.Spec.MaxUnavailable, newPdb.Spec.MaxUnavailable)
+	}
+	return true, nil
+}
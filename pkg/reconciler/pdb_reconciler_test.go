@@ -0,0 +1,158 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestPodDisruptionBudgetReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default", UID: "rbg-uid"},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{
+				{
+					Name:             "prefill",
+					DisruptionBudget: &workloadsv1alpha1.DisruptionBudget{},
+				},
+				{
+					Name: "decode",
+					DisruptionBudget: &workloadsv1alpha1.DisruptionBudget{
+						MinAvailable: ptr.To(intstr.FromString("50%")),
+					},
+				},
+				{Name: "router"},
+			},
+		},
+	}
+	controllerRef := []metav1.OwnerReference{
+		*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+	}
+
+	// decode has an outdated budget, router has no budget any more
+	oldDecode := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-decode", Namespace: "default", OwnerReferences: controllerRef,
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha1.SetRoleLabelKey: "decode",
+			},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{MaxUnavailable: ptr.To(intstr.FromInt32(1))},
+	}
+	oldRouter := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-router", Namespace: "default", OwnerReferences: controllerRef,
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha1.SetRoleLabelKey: "router",
+			},
+		},
+	}
+	// not managed by the rbg
+	userPdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "user-pdb", Namespace: "default",
+			Labels: map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(rbg, oldDecode, oldRouter, userPdb).Build()
+	r := NewPodDisruptionBudgetReconciler(fakeClient)
+	assert.NoError(t, r.Reconcile(context.TODO(), rbg))
+
+	getPdb := func(name string) (*policyv1.PodDisruptionBudget, error) {
+		pdb := &policyv1.PodDisruptionBudget{}
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, pdb)
+		return pdb, err
+	}
+
+	prefill, err := getPdb("test-rbg-prefill")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+		workloadsv1alpha1.SetRoleLabelKey: "prefill",
+	}, prefill.Spec.Selector.MatchLabels)
+	assert.Nil(t, prefill.Spec.MinAvailable)
+	assert.Equal(t, ptr.To(intstr.FromInt32(1)), prefill.Spec.MaxUnavailable)
+	assert.True(t, metav1.IsControlledBy(prefill, rbg))
+
+	decode, err := getPdb("test-rbg-decode")
+	assert.NoError(t, err)
+	assert.Equal(t, ptr.To(intstr.FromString("50%")), decode.Spec.MinAvailable)
+	assert.Nil(t, decode.Spec.MaxUnavailable)
+	assert.Equal(t, "decode", decode.Spec.Selector.MatchLabels[workloadsv1alpha1.SetRoleLabelKey])
+
+	_, err = getPdb("test-rbg")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	_, err = getPdb("test-rbg-router")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	_, err = getPdb("user-pdb")
+	assert.NoError(t, err)
+}
+
+func TestPodDisruptionBudgetReconciler_GroupBudget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	// the budgets of the roles are ignored, a pod selected by two budgets cannot be evicted
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default", UID: "rbg-uid"},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{
+				{
+					Name:             "prefill",
+					DisruptionBudget: &workloadsv1alpha1.DisruptionBudget{},
+				},
+				{Name: "decode"},
+			},
+			DisruptionBudget: &workloadsv1alpha1.DisruptionBudget{
+				MaxUnavailable: ptr.To(intstr.FromInt32(2)),
+			},
+		},
+	}
+	oldPrefill := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-prefill", Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+			},
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha1.SetRoleLabelKey: "prefill",
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rbg, oldPrefill).Build()
+	r := NewPodDisruptionBudgetReconciler(fakeClient)
+	assert.NoError(t, r.Reconcile(context.TODO(), rbg))
+
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), pdbList))
+	assert.Len(t, pdbList.Items, 1)
+	group := pdbList.Items[0]
+	assert.Equal(t, "test-rbg", group.Name)
+	assert.Equal(t, map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg"}, group.Spec.Selector.MatchLabels)
+	assert.Equal(t, ptr.To(intstr.FromInt32(2)), group.Spec.MaxUnavailable)
+	assert.True(t, metav1.IsControlledBy(&group, rbg))
+}
@@ -50,24 +50,36 @@ func (r *PodReconciler) ConstructPodTemplateSpecApplyConfiguration(
 	// inject objects
 	injector := discovery.NewDefaultInjector(r.scheme, r.client)
 	if r.injectObjects == nil {
-		r.injectObjects = []string{"config", "sidecar", "env"}
+		r.injectObjects = []string{"config", "sidecar", "env", "template", "barrier"}
 	}
-	if utils.ContainsString(r.injectObjects, "config") {
+	if utils.ContainsString(r.injectObjects, "config") && role.InjectConfigEnabled() {
 		if err := injector.InjectConfig(ctx, &podTemplateSpec, rbg, role); err != nil {
 			return nil, fmt.Errorf("failed to inject config: %w", err)
 		}
 	}
-	if utils.ContainsString(r.injectObjects, "sidecar") {
+	if utils.ContainsString(r.injectObjects, "sidecar") && role.InjectSidecarEnabled() {
 		// The sidecar containers also need rbg-related envs, so inject them first
 		if err := injector.InjectSidecar(ctx, &podTemplateSpec, rbg, role); err != nil {
 			return nil, fmt.Errorf("failed to inject sidecar: %w", err)
 		}
 	}
-	if utils.ContainsString(r.injectObjects, "env") {
+	if utils.ContainsString(r.injectObjects, "env") && role.InjectEnvEnabled() {
 		if err := injector.InjectEnv(ctx, &podTemplateSpec, rbg, role); err != nil {
 			return nil, fmt.Errorf("failed to inject env vars: %w", err)
 		}
 	}
+	// Render the templates last, so that they are also rendered in the injected sidecars
+	if utils.ContainsString(r.injectObjects, "template") && role.RenderTemplatesEnabled() {
+		if err := injector.RenderTemplates(ctx, &podTemplateSpec, rbg, role); err != nil {
+			return nil, fmt.Errorf("failed to render templates: %w", err)
+		}
+	}
+
+	if utils.ContainsString(r.injectObjects, "barrier") {
+		if err := injector.InjectStartupBarrier(ctx, &podTemplateSpec, rbg, role); err != nil {
+			return nil, fmt.Errorf("failed to inject startup barrier: %w", err)
+		}
+	}
 
 	// construct pod template spec configuration
 	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podTemplateSpec)
@@ -80,12 +92,29 @@ func (r *PodReconciler) ConstructPodTemplateSpecApplyConfiguration(
 		return nil, err
 	}
 
-	if rbg.EnableGangScheduling() {
+	// the rbgs of a rbgset with gang scheduling are labeled with the PodGroup of the rbgset
+	if podGroup, ok := rbg.Labels[workloadsv1alpha1.PodGroupLabelKey]; ok {
+		if podLabels == nil {
+			podLabels = map[string]string{}
+		}
+		podLabels[workloadsv1alpha1.PodGroupLabelKey] = podGroup
+	} else if rbg.EnableGangScheduling() {
 		if podLabels == nil {
 			podLabels = map[string]string{}
 		}
 		podLabels[workloadsv1alpha1.PodGroupLabelKey] = rbg.Name
 	}
+	// the pods of the rbgs of a rbgset are labeled with the set, so that they can be selected by the
+	// scale subresource of the rbgset.
+	if rbgSetName, ok := rbg.Labels[workloadsv1alpha1.SetRBGSetNameLabelKey]; ok {
+		if podLabels == nil {
+			podLabels = map[string]string{}
+		}
+		podLabels[workloadsv1alpha1.SetRBGSetNameLabelKey] = rbgSetName
+		if index, ok := rbg.Labels[workloadsv1alpha1.SetRBGIndexLabelKey]; ok {
+			podLabels[workloadsv1alpha1.SetRBGIndexLabelKey] = index
+		}
+	}
 	podTemplateApplyConfiguration.WithLabels(podLabels)
 
 	return podTemplateApplyConfiguration, nil
@@ -137,6 +166,16 @@ func podSpecEqual(spec1, spec2 corev1.PodSpec) (bool, error) {
 		}
 	}
 
+	// init containers run in order, so they are compared without sorting
+	if len(spec1.InitContainers) != len(spec2.InitContainers) {
+		return false, fmt.Errorf("pod template spec init containers len not equal")
+	}
+	for i := range spec1.InitContainers {
+		if equal, err := containerEqual(spec1.InitContainers[i], spec2.InitContainers[i]); !equal {
+			return false, fmt.Errorf("init container not equal: %s", err.Error())
+		}
+	}
+
 	if equal, err := volumesEqual(spec1.Volumes, spec2.Volumes); !equal {
 		return false, fmt.Errorf("podTemplate volumes not equal: %s", err.Error())
 	}
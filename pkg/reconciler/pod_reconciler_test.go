@@ -1,9 +1,18 @@
 package reconciler
 
 import (
+	"context"
+	"reflect"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
 )
 
 func Test_objectMetaEqual(t *testing.T) {
@@ -40,6 +49,25 @@ func Test_objectMetaEqual(t *testing.T) {
 			want:    true,
 			wantErr: false,
 		},
+		{
+			name: "test rbgset labels",
+			args: args{
+				meta1: v1.ObjectMeta{
+					Labels: map[string]string{
+						"rolebasedgroupset.workloads.x-k8s.io/name":      "test-rbgset",
+						"rolebasedgroupset.workloads.x-k8s.io/rbg-index": "0",
+						"rolebasedgroup.workloads.x-k8s.io/name":         "test-rbgset-0",
+					},
+				},
+				meta2: v1.ObjectMeta{
+					Labels: map[string]string{
+						"rolebasedgroup.workloads.x-k8s.io/name": "test-rbgset-0",
+					},
+				},
+			},
+			want:    true,
+			wantErr: false,
+		},
 		{
 			name: "test system annotations",
 			args: args{
@@ -87,3 +115,252 @@ func Test_objectMetaEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestConstructPodTemplateSpecApplyConfiguration_RBGSetLabels(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	role := &workloadsv1alpha1.RoleSpec{
+		Name:     "prefill",
+		Replicas: ptr.To(int32(1)),
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx"}}},
+		},
+	}
+	tests := []struct {
+		name      string
+		rbgLabels map[string]string
+		expected  map[string]string
+	}{
+		{
+			name:     "standalone rbg",
+			expected: map[string]string{"app": "test"},
+		},
+		{
+			name: "rbg of a rbgset",
+			rbgLabels: map[string]string{
+				workloadsv1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+				workloadsv1alpha1.SetRBGIndexLabelKey:   "1",
+			},
+			expected: map[string]string{
+				"app":                                   "test",
+				workloadsv1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+				workloadsv1alpha1.SetRBGIndexLabelKey:   "1",
+			},
+		},
+		{
+			name: "rbg of a rbgset with gang scheduling",
+			rbgLabels: map[string]string{
+				workloadsv1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+				workloadsv1alpha1.SetRBGIndexLabelKey:   "1",
+				workloadsv1alpha1.PodGroupLabelKey:      "test-rbgset",
+			},
+			expected: map[string]string{
+				"app":                                   "test",
+				workloadsv1alpha1.SetRBGSetNameLabelKey: "test-rbgset",
+				workloadsv1alpha1.SetRBGIndexLabelKey:   "1",
+				workloadsv1alpha1.PodGroupLabelKey:      "test-rbgset",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rbg := &workloadsv1alpha1.RoleBasedGroup{
+				ObjectMeta: v1.ObjectMeta{Name: "test-rbgset-1", Namespace: "default", Labels: tt.rbgLabels},
+				Spec:       workloadsv1alpha1.RoleBasedGroupSpec{Roles: []workloadsv1alpha1.RoleSpec{*role}},
+			}
+			r := NewPodReconciler(scheme, fake.NewClientBuilder().WithScheme(scheme).Build())
+			r.SetInjectors([]string{})
+			got, err := r.ConstructPodTemplateSpecApplyConfiguration(
+				context.TODO(), rbg, role, map[string]string{"app": "test"})
+			if err != nil {
+				t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Labels, tt.expected) {
+				t.Errorf("ConstructPodTemplateSpecApplyConfiguration() labels = %v, want %v", got.Labels, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConstructPodTemplateSpecApplyConfiguration_InjectionDisabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	role := &workloadsv1alpha1.RoleSpec{
+		Name:     "prefill",
+		Replicas: ptr.To(int32(1)),
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "nginx"}}},
+		},
+		EngineRuntimes: []workloadsv1alpha1.EngineRuntime{{ProfileName: "patio-runtime"}},
+		Injection: &workloadsv1alpha1.InjectionPolicy{
+			Config:  ptr.To(false),
+			Sidecar: ptr.To(false),
+			Env:     ptr.To(false),
+		},
+	}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: v1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+		Spec:       workloadsv1alpha1.RoleBasedGroupSpec{Roles: []workloadsv1alpha1.RoleSpec{*role}},
+	}
+
+	// the profile of the engine runtime does not exist, the reconcile fails if the sidecar is injected
+	r := NewPodReconciler(scheme, fake.NewClientBuilder().WithScheme(scheme).Build())
+	got, err := r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil)
+	if err != nil {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+	}
+	if len(got.Spec.Volumes) != 0 || len(got.Spec.Containers) != 1 || len(got.Spec.Containers[0].Env) != 0 {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() injected into pod spec %v", got.Spec)
+	}
+}
+
+func TestConstructPodTemplateSpecApplyConfiguration_StartupBarrier(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	role := &workloadsv1alpha1.RoleSpec{
+		Name:         "decode",
+		Replicas:     ptr.To(int32(1)),
+		Dependencies: []string{"prefill", "router"},
+		StartupBarrier: &workloadsv1alpha1.StartupBarrier{
+			Image:          "rbgs-controller:v1",
+			TimeoutSeconds: ptr.To(int32(600)),
+		},
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "download", Image: "busybox"}},
+				Containers:     []corev1.Container{{Name: "main", Image: "nginx"}},
+			},
+		},
+		Injection: &workloadsv1alpha1.InjectionPolicy{Config: ptr.To(false)},
+	}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: v1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+		Spec:       workloadsv1alpha1.RoleBasedGroupSpec{Roles: []workloadsv1alpha1.RoleSpec{*role}},
+	}
+
+	r := NewPodReconciler(scheme, fake.NewClientBuilder().WithScheme(scheme).Build())
+	got, err := r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil)
+	if err != nil {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+	}
+	if len(got.Spec.InitContainers) != 2 || *got.Spec.InitContainers[1].Name != "download" {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() init containers = %v", got.Spec.InitContainers)
+	}
+	barrier := got.Spec.InitContainers[0]
+	wantArgs := []string{"--wait-for-roles=prefill,router", "--group=test-rbg", "--wait-timeout=600s"}
+	if *barrier.Name != "rbg-startup-barrier" || *barrier.Image != "rbgs-controller:v1" ||
+		!reflect.DeepEqual(barrier.Args, wantArgs) {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() barrier = %s %s %v", *barrier.Name, *barrier.Image,
+			barrier.Args)
+	}
+
+	// the barrier waits for the ready roles of dependsOn with their thresholds, but not for the other dependencies
+	role.Dependencies = nil
+	role.DependsOn = []workloadsv1alpha1.Dependency{
+		{Role: "prefill", Threshold: ptr.To(intstr.FromString("50%"))},
+		{Role: "router", Condition: workloadsv1alpha1.CreatedDependencyCondition},
+		{Object: &workloadsv1alpha1.DependencyObject{Kind: workloadsv1alpha1.SecretDependencyObject, Name: "token"}},
+	}
+	got, err = r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil)
+	if err != nil {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+	}
+	if args := got.Spec.InitContainers[0].Args; args[0] != "--wait-for-roles=prefill=50%" {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() barrier args = %v", args)
+	}
+
+	// roles without dependencies on ready roles have nothing to wait for
+	role.DependsOn = role.DependsOn[1:]
+	got, err = r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil)
+	if err != nil {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+	}
+	if len(got.Spec.InitContainers) != 1 {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() init containers = %v", got.Spec.InitContainers)
+	}
+}
+
+func TestConstructPodTemplateSpecApplyConfiguration_RenderTemplates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	role := &workloadsv1alpha1.RoleSpec{
+		Name:     "decode",
+		Replicas: ptr.To(int32(2)),
+		Workload: workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "main", Image: "nginx", Args: []string{"--node-rank={{ .Role.Index }}"}},
+				},
+			},
+		},
+		Injection: &workloadsv1alpha1.InjectionPolicy{Config: ptr.To(false), RenderTemplates: true},
+	}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: v1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+		Spec:       workloadsv1alpha1.RoleBasedGroupSpec{Roles: []workloadsv1alpha1.RoleSpec{*role}},
+	}
+
+	// the index refers to the env var ROLE_INDEX injected into the rendered container
+	r := NewPodReconciler(scheme, fake.NewClientBuilder().WithScheme(scheme).Build())
+	got, err := r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil)
+	if err != nil {
+		t.Fatalf("ConstructPodTemplateSpecApplyConfiguration() error = %v", err)
+	}
+	container := got.Spec.Containers[0]
+	if !reflect.DeepEqual(container.Args, []string{"--node-rank=$(ROLE_INDEX)"}) {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() args = %v", container.Args)
+	}
+	hasRoleIndex := false
+	for _, env := range container.Env {
+		hasRoleIndex = hasRoleIndex || *env.Name == "ROLE_INDEX"
+	}
+	if !hasRoleIndex {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() env = %v, want ROLE_INDEX", container.Env)
+	}
+
+	// without the env var, the index cannot be expanded
+	role.Injection.Env = ptr.To(false)
+	if _, err := r.ConstructPodTemplateSpecApplyConfiguration(context.TODO(), rbg, role, nil); err == nil {
+		t.Errorf("ConstructPodTemplateSpecApplyConfiguration() error = nil with the env injection disabled")
+	}
+}
+
+func Test_podSpecEqual_InitContainers(t *testing.T) {
+	main := []corev1.Container{{Name: "main", Image: "nginx"}}
+	barrier := corev1.Container{Name: "rbg-startup-barrier", Image: "rbgs-controller:v1"}
+	download := corev1.Container{Name: "download", Image: "busybox"}
+
+	tests := []struct {
+		name  string
+		init1 []corev1.Container
+		init2 []corev1.Container
+		want  bool
+	}{
+		{name: "equal", init1: []corev1.Container{barrier, download}, init2: []corev1.Container{barrier, download},
+			want: true},
+		{name: "added", init1: []corev1.Container{download}, init2: []corev1.Container{barrier, download},
+			want: false},
+		{name: "reordered", init1: []corev1.Container{download, barrier}, init2: []corev1.Container{barrier, download},
+			want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := podSpecEqual(
+				corev1.PodSpec{InitContainers: tt.init1, Containers: main},
+				corev1.PodSpec{InitContainers: tt.init2, Containers: main},
+			)
+			if got != tt.want {
+				t.Errorf("podSpecEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+	"sigs.k8s.io/rbgs/pkg/discovery"
+)
+
+const (
+	// registrationRequestTimeout bounds a request, and registrationTimeout all the requests of a reconcile, so that
+	// an unresponsive instance does not stall the reconcile of the rbg.
+	registrationRequestTimeout = 2 * time.Second
+	registrationTimeout        = 5 * time.Second
+
+	// RegistrationRetryInterval is the delay after which the rbg is reconciled again when a request failed.
+	RegistrationRetryInterval = 10 * time.Second
+)
+
+// RegistrationReconciler registers the ready instances of the backend roles into the HTTP API of the ready instances
+// of the roles with registration, e.g. the prefill and decode workers into a router.
+type RegistrationReconciler struct {
+	client     client.Client
+	httpClient *http.Client
+	// timeout of all the requests of a reconcile
+	timeout time.Duration
+}
+
+func NewRegistrationReconciler(client client.Client) *RegistrationReconciler {
+	return &RegistrationReconciler{
+		client:     client,
+		httpClient: &http.Client{Timeout: registrationRequestTimeout},
+		timeout:    registrationTimeout,
+	}
+}
+
+// registrationBackend is the data the registration requests are rendered with.
+type registrationBackend struct {
+	Role    string
+	Name    string
+	Address string
+	Port    int32
+	URL     string
+}
+
+// registrationTask holds the requests to send to an instance, and the index of the status of the instance.
+type registrationTask struct {
+	index      int
+	baseURL    string
+	register   *workloadsv1alpha1.RegistrationRequest
+	deregister *workloadsv1alpha1.RegistrationRequest
+	// the backends to deregister, whose registration is known or unknown
+	deregisterKnown, deregisterUnknown []workloadsv1alpha1.RegisteredBackend
+	registerBackends                   []workloadsv1alpha1.RegisteredBackend
+}
+
+// Reconcile registers the ready backends into the ready instances of the roles with registration, and deregisters
+// the backends which are not ready anymore or whose registration is removed. It returns the backends registered into
+// each instance. The backends about to be registered or deregistered are recorded in the status of rbg before the
+// requests are sent, and the requests to the instances are sent in parallel within a deadline. A failed request is
+// left to the next reconcile, so the registrations are returned even with an error.
+func (r *RegistrationReconciler) Reconcile(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup,
+) ([]workloadsv1alpha1.RegistrationStatus, error) {
+	if !rbg.RegistersBackends() && len(rbg.Status.Registrations) == 0 {
+		return nil, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(ctx, podList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name},
+	); err != nil {
+		return rbg.Status.Registrations, err
+	}
+	pods := make(map[string]*corev1.Pod, len(podList.Items))
+	for i := range podList.Items {
+		pods[podList.Items[i].Name] = &podList.Items[i]
+	}
+	registered := make(map[string]workloadsv1alpha1.RegistrationStatus, len(rbg.Status.Registrations))
+	for _, status := range rbg.Status.Registrations {
+		registered[status.Role+"/"+status.Instance] = status
+	}
+
+	var statuses []workloadsv1alpha1.RegistrationStatus
+	var tasks []registrationTask
+	var errs []error
+	for i := range rbg.Spec.Roles {
+		role := &rbg.Spec.Roles[i]
+		if role.Registration == nil {
+			continue
+		}
+		backends, err := registrationBackends(rbg, role.Registration, podList.Items)
+		if err != nil {
+			// keep the registrations of the role as they are until its registration is fixed
+			errs = append(errs, fmt.Errorf("invalid registration of role %s: %w", role.Name, err))
+			for _, status := range rbg.Status.Registrations {
+				if status.Role == role.Name {
+					statuses = append(statuses, status)
+				}
+			}
+			continue
+		}
+
+		for _, instance := range discovery.ReadyInstances(rbg, role, podList.Items) {
+			status := workloadsv1alpha1.RegistrationStatus{
+				Role:       role.Name,
+				Instance:   instance.Name,
+				ReadyTime:  podReadyTime(pods[instance.Name]),
+				Port:       role.Registration.Port,
+				Deregister: role.Registration.Deregister.DeepCopy(),
+			}
+			// an instance which became ready again may have restarted and lost its registrations
+			if old, ok := registered[role.Name+"/"+instance.Name]; ok && old.ReadyTime.Equal(status.ReadyTime) {
+				status.Backends = old.Backends
+				status.Pending = old.Pending
+			}
+			task := planRegistration(&status, &role.Registration.Register, status.Deregister, backends)
+			task.index, task.baseURL = len(statuses), registrationBaseURL(instance.IP, status.Port)
+			statuses = append(statuses, status)
+			tasks = append(tasks, task)
+		}
+	}
+
+	// the backends of the roles whose registration is removed are deregistered from the instances still ready
+	for _, old := range rbg.Status.Registrations {
+		if role, err := rbg.GetRole(old.Role); err == nil && role.Registration != nil {
+			continue
+		}
+		pod := pods[old.Instance]
+		if old.Deregister == nil || pod == nil || pod.Status.PodIP == "" || !old.ReadyTime.Equal(podReadyTime(pod)) {
+			continue
+		}
+		status := *old.DeepCopy()
+		task := planRegistration(&status, nil, status.Deregister, nil)
+		if len(status.Backends) == 0 && len(status.Pending) == 0 {
+			continue
+		}
+		task.index, task.baseURL = len(statuses), registrationBaseURL(pod.Status.PodIP, status.Port)
+		statuses = append(statuses, status)
+		tasks = append(tasks, task)
+	}
+
+	if err := r.recordPending(ctx, rbg, statuses); err != nil {
+		return rbg.Status.Registrations, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	taskErrs := make([]error, len(tasks))
+	var wg sync.WaitGroup
+	for i := range tasks {
+		wg.Add(1)
+		go func(task *registrationTask, status *workloadsv1alpha1.RegistrationStatus) {
+			defer wg.Done()
+			if err := r.runTask(ctx, task, status); err != nil {
+				taskErrs[i] = fmt.Errorf("failed to register backends into %s: %w", status.Instance, err)
+			}
+		}(&tasks[i], &statuses[tasks[i].index])
+	}
+	wg.Wait()
+
+	// the roles whose registration is removed are forgotten once their backends are deregistered
+	statuses = slices.DeleteFunc(statuses, func(status workloadsv1alpha1.RegistrationStatus) bool {
+		role, err := rbg.GetRole(status.Role)
+		removed := err != nil || role.Registration == nil
+		return removed && len(status.Backends) == 0 && len(status.Pending) == 0
+	})
+	return statuses, utilerrors.NewAggregate(append(errs, taskErrs...))
+}
+
+// planRegistration returns the requests which bring the backends registered into the instance of status to desired,
+// and moves the backends of the requests from the backends of status to its pending ones. A pending backend may or
+// may not be registered, so it is deregistered first if the registration has a deregister request, and registered
+// again if it is desired. Without a deregister request, the backends not desired anymore are only forgotten.
+func planRegistration(
+	status *workloadsv1alpha1.RegistrationStatus, register, deregister *workloadsv1alpha1.RegistrationRequest,
+	desired []workloadsv1alpha1.RegisteredBackend,
+) registrationTask {
+	task := registrationTask{register: register, deregister: deregister}
+	kept := make([]workloadsv1alpha1.RegisteredBackend, 0, len(status.Backends))
+	for _, backend := range status.Backends {
+		if slices.Contains(desired, backend) {
+			kept = append(kept, backend)
+		} else if deregister != nil {
+			task.deregisterKnown = append(task.deregisterKnown, backend)
+		}
+	}
+	if deregister != nil {
+		task.deregisterUnknown = status.Pending
+	}
+	for _, backend := range desired {
+		if !slices.Contains(kept, backend) {
+			task.registerBackends = append(task.registerBackends, backend)
+		}
+	}
+
+	var pending []workloadsv1alpha1.RegisteredBackend
+	for _, backends := range [][]workloadsv1alpha1.RegisteredBackend{
+		task.deregisterKnown, task.deregisterUnknown, task.registerBackends,
+	} {
+		for _, backend := range backends {
+			if !slices.Contains(pending, backend) {
+				pending = append(pending, backend)
+			}
+		}
+	}
+	status.Backends = kept
+	status.Pending = pending
+	return task
+}
+
+// recordPending patches the registrations of rbg to statuses before the requests are sent, if any backend is about to
+// be registered or deregistered.
+func (r *RegistrationReconciler) recordPending(
+	ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup, statuses []workloadsv1alpha1.RegistrationStatus,
+) error {
+	if !slices.ContainsFunc(statuses, func(status workloadsv1alpha1.RegistrationStatus) bool {
+		return len(status.Pending) > 0
+	}) || equality.Semantic.DeepEqual(rbg.Status.Registrations, statuses) {
+		return nil
+	}
+	// only the registrations are patched, the rest of the status of rbg is being computed by the reconcile
+	obj := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: rbg.Name, Namespace: rbg.Namespace},
+	}
+	patch := client.MergeFrom(obj.DeepCopy())
+	obj.Status.Registrations = statuses
+	if err := r.client.Status().Patch(ctx, obj, patch); err != nil {
+		return fmt.Errorf("failed to record the pending registrations: %w", err)
+	}
+	return nil
+}
+
+// runTask sends the requests of task to its instance and updates status: the backends deregistered are removed
+// from the pending ones, and the backends registered are moved to the registered ones. It stops sending requests to
+// the instance after a failed request, so the remaining backends stay pending.
+func (r *RegistrationReconciler) runTask(
+	ctx context.Context, task *registrationTask, status *workloadsv1alpha1.RegistrationStatus,
+) error {
+	for _, backend := range task.deregisterKnown {
+		if err := r.send(ctx, task.baseURL, task.deregister, backend, false); err != nil {
+			return err
+		}
+		status.Pending = removeBackend(status.Pending, backend)
+	}
+	for _, backend := range task.deregisterUnknown {
+		// the backend may not be registered, so any response of the instance is fine
+		if err := r.send(ctx, task.baseURL, task.deregister, backend, true); err != nil {
+			return err
+		}
+		if !slices.Contains(task.registerBackends, backend) {
+			status.Pending = removeBackend(status.Pending, backend)
+		}
+	}
+	for _, backend := range task.registerBackends {
+		if err := r.send(ctx, task.baseURL, task.register, backend, false); err != nil {
+			return err
+		}
+		status.Pending = removeBackend(status.Pending, backend)
+		status.Backends = append(status.Backends, backend)
+	}
+	return nil
+}
+
+func removeBackend(
+	backends []workloadsv1alpha1.RegisteredBackend, backend workloadsv1alpha1.RegisteredBackend,
+) []workloadsv1alpha1.RegisteredBackend {
+	backends = slices.DeleteFunc(backends, func(b workloadsv1alpha1.RegisteredBackend) bool { return b == backend })
+	if len(backends) == 0 {
+		return nil
+	}
+	return backends
+}
+
+// registrationBackends returns the ready instances of the backend roles of registration.
+func registrationBackends(
+	rbg *workloadsv1alpha1.RoleBasedGroup, registration *workloadsv1alpha1.RoleRegistration, pods []corev1.Pod,
+) ([]workloadsv1alpha1.RegisteredBackend, error) {
+	var backends []workloadsv1alpha1.RegisteredBackend
+	for _, backend := range registration.Backends {
+		role, err := rbg.GetRole(backend.Role)
+		if err != nil {
+			return nil, err
+		}
+		port := backend.Port
+		if port == 0 {
+			if len(role.ServicePorts) == 0 {
+				return nil, fmt.Errorf("port of backend role %s is not set and the role has no service port", role.Name)
+			}
+			port = role.ServicePorts[0].Port
+		}
+		for _, instance := range discovery.ReadyInstances(rbg, role, pods) {
+			backends = append(backends, workloadsv1alpha1.RegisteredBackend{
+				Role:    role.Name,
+				Name:    instance.Name,
+				Address: instance.Address,
+				Port:    port,
+			})
+		}
+	}
+	return backends, nil
+}
+
+func registrationBaseURL(ip string, port int32) string {
+	return "http://" + net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
+
+// send sends request for backend to the instance at baseURL. A response other than 2xx is an error unless
+// anyStatus is set.
+func (r *RegistrationReconciler) send(
+	ctx context.Context, baseURL string, request *workloadsv1alpha1.RegistrationRequest,
+	backend workloadsv1alpha1.RegisteredBackend, anyStatus bool,
+) error {
+	logger := log.FromContext(ctx)
+
+	data := registrationBackend{
+		Role:    backend.Role,
+		Name:    backend.Name,
+		Address: backend.Address,
+		Port:    backend.Port,
+		URL:     "http://" + net.JoinHostPort(backend.Address, strconv.Itoa(int(backend.Port))),
+	}
+	path, err := renderRegistrationTemplate(request.Path, data)
+	if err != nil {
+		return err
+	}
+	url := baseURL + string(path)
+	body, err := renderRegistrationTemplate(request.Body, data)
+	if err != nil {
+		return err
+	}
+	method := request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if !anyStatus && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return fmt.Errorf("%s %s: unexpected status %s", method, url, resp.Status)
+	}
+	logger.Info("registration request sent", "method", method, "url", url, "backend", backend.Name,
+		"status", resp.StatusCode)
+	return nil
+}
+
+func renderRegistrationTemplate(text string, data registrationBackend) ([]byte, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("registration").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// podReadyTime returns the time pod became ready.
+func podReadyTime(pod *corev1.Pod) *metav1.Time {
+	if pod == nil {
+		return nil
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return cond.LastTransitionTime.DeepCopy()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package reconciler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// fakeRouter records the registration requests it receives, and fails them while failing is set.
+type fakeRouter struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+	failing  bool
+}
+
+func newFakeRouter(t *testing.T) *fakeRouter {
+	router := &fakeRouter{}
+	router.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.mu.Lock()
+		defer router.mu.Unlock()
+		if router.failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		router.requests = append(router.requests, r.Method+" "+r.URL.RequestURI())
+	}))
+	t.Cleanup(router.Close)
+	return router
+}
+
+func (r *fakeRouter) port(t *testing.T) int32 {
+	_, port, err := net.SplitHostPort(r.Listener.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return int32(p)
+}
+
+func (r *fakeRouter) setFailing(failing bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failing = failing
+}
+
+func (r *fakeRouter) takeRequests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests := r.requests
+	r.requests = nil
+	return requests
+}
+
+func buildRegistrationRBG(routerPort int32) *workloadsv1alpha1.RoleBasedGroup {
+	sts := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	return &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{
+				{
+					Name:     "router",
+					Workload: sts,
+					Registration: &workloadsv1alpha1.RoleRegistration{
+						Backends: []workloadsv1alpha1.RegistrationBackend{{Role: "prefill"}, {Role: "decode", Port: 9000}},
+						Port:     routerPort,
+						Register: workloadsv1alpha1.RegistrationRequest{
+							Path: "/add_worker?url={{ urlquery .URL }}&type={{ .Role }}",
+						},
+						Deregister: &workloadsv1alpha1.RegistrationRequest{
+							Path: "/remove_worker?url={{ urlquery .URL }}",
+						},
+					},
+				},
+				{
+					Name:         "prefill",
+					Workload:     sts,
+					ServicePorts: []corev1.ServicePort{{Name: "http", Port: 8000}},
+				},
+				{
+					Name:     "decode",
+					Workload: sts,
+				},
+			},
+		},
+	}
+}
+
+func buildRegistrationPod(name, role, ip string, readyTime time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+				workloadsv1alpha1.SetRoleLabelKey: role,
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: ip},
+	}
+	if !readyTime.IsZero() {
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(readyTime),
+		}}
+	}
+	return pod
+}
+
+func newRegistrationClient(
+	scheme *runtime.Scheme, rbg *workloadsv1alpha1.RoleBasedGroup, pods ...client.Object,
+) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(append(pods, rbg.DeepCopy())...).WithStatusSubresource(rbg).Build()
+}
+
+func TestRegistrationReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+	readyTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	prefill0 := workloadsv1alpha1.RegisteredBackend{Role: "prefill", Name: "prefill-0", Address: "10.0.0.1", Port: 8000}
+	prefill1 := workloadsv1alpha1.RegisteredBackend{Role: "prefill", Name: "prefill-1", Address: "10.0.0.2", Port: 8000}
+	decode0 := workloadsv1alpha1.RegisteredBackend{Role: "decode", Name: "decode-0", Address: "10.0.0.3", Port: 9000}
+
+	t.Run("Register the ready backends", func(t *testing.T) {
+		router := newFakeRouter(t)
+		rbg := buildRegistrationRBG(router.port(t))
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", readyTime),
+			buildRegistrationPod("prefill-1", "prefill", "10.0.0.2", time.Time{}),
+			buildRegistrationPod("decode-0", "decode", "10.0.0.3", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.1%3A8000&type=prefill",
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.3%3A9000&type=decode",
+		}, router.takeRequests())
+		require.Len(t, statuses, 1)
+		assert.Equal(t, "router", statuses[0].Role)
+		assert.Equal(t, "router-0", statuses[0].Instance)
+		assert.True(t, statuses[0].ReadyTime.Time.Equal(readyTime))
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0, decode0}, statuses[0].Backends)
+
+		// nothing changes
+		rbg.Status.Registrations = statuses
+		statuses, err = NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Empty(t, router.takeRequests())
+		assert.Equal(t, rbg.Status.Registrations, statuses)
+	})
+
+	t.Run("Deregister the backends not ready anymore", func(t *testing.T) {
+		router := newFakeRouter(t)
+		rbg := buildRegistrationRBG(router.port(t))
+		rbg.Status.Registrations = []workloadsv1alpha1.RegistrationStatus{{
+			Role: "router", Instance: "router-0", ReadyTime: &metav1.Time{Time: readyTime},
+			Backends: []workloadsv1alpha1.RegisteredBackend{prefill0, decode0},
+		}}
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", time.Time{}),
+			buildRegistrationPod("prefill-1", "prefill", "10.0.0.2", readyTime),
+			buildRegistrationPod("decode-0", "decode", "10.0.0.3", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"POST /remove_worker?url=http%3A%2F%2F10.0.0.1%3A8000",
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.2%3A8000&type=prefill",
+		}, router.takeRequests())
+		require.Len(t, statuses, 1)
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{decode0, prefill1}, statuses[0].Backends)
+	})
+
+	t.Run("Register again into a restarted router", func(t *testing.T) {
+		router := newFakeRouter(t)
+		rbg := buildRegistrationRBG(router.port(t))
+		rbg.Status.Registrations = []workloadsv1alpha1.RegistrationStatus{{
+			Role: "router", Instance: "router-0", ReadyTime: &metav1.Time{Time: readyTime},
+			Backends: []workloadsv1alpha1.RegisteredBackend{prefill0},
+		}}
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime.Add(time.Minute)),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.1%3A8000&type=prefill",
+		}, router.takeRequests())
+		require.Len(t, statuses, 1)
+		assert.True(t, statuses[0].ReadyTime.Time.Equal(readyTime.Add(time.Minute)))
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0}, statuses[0].Backends)
+	})
+
+	t.Run("Retry the failed registrations", func(t *testing.T) {
+		router := newFakeRouter(t)
+		router.setFailing(true)
+		rbg := buildRegistrationRBG(router.port(t))
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", readyTime),
+			buildRegistrationPod("decode-0", "decode", "10.0.0.3", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		assert.ErrorContains(t, err, "503")
+		require.Len(t, statuses, 1)
+		assert.Empty(t, statuses[0].Backends)
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0, decode0}, statuses[0].Pending)
+
+		// the pending backends are recorded before the requests are sent
+		recorded := &workloadsv1alpha1.RoleBasedGroup{}
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(rbg), recorded))
+		require.Len(t, recorded.Status.Registrations, 1)
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0, decode0},
+			recorded.Status.Registrations[0].Pending)
+
+		// a pending backend may be registered, so it is deregistered before it is registered again
+		router.setFailing(false)
+		rbg.Status.Registrations = statuses
+		statuses, err = NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"POST /remove_worker?url=http%3A%2F%2F10.0.0.1%3A8000",
+			"POST /remove_worker?url=http%3A%2F%2F10.0.0.3%3A9000",
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.1%3A8000&type=prefill",
+			"POST /add_worker?url=http%3A%2F%2F10.0.0.3%3A9000&type=decode",
+		}, router.takeRequests())
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0, decode0}, statuses[0].Backends)
+		assert.Empty(t, statuses[0].Pending)
+	})
+
+	t.Run("Deregister the backends when the registration is removed", func(t *testing.T) {
+		router := newFakeRouter(t)
+		rbg := buildRegistrationRBG(router.port(t))
+		rbg.Status.Registrations = []workloadsv1alpha1.RegistrationStatus{{
+			Role: "router", Instance: "router-0", ReadyTime: &metav1.Time{Time: readyTime},
+			Backends:   []workloadsv1alpha1.RegisteredBackend{prefill0},
+			Port:       router.port(t),
+			Deregister: rbg.Spec.Roles[0].Registration.Deregister.DeepCopy(),
+		}}
+		rbg.Spec.Roles[0].Registration = nil
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"POST /remove_worker?url=http%3A%2F%2F10.0.0.1%3A8000"}, router.takeRequests())
+		assert.Empty(t, statuses)
+	})
+
+	t.Run("Give up the requests after the deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		hanging := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { <-release }))
+		t.Cleanup(hanging.Close)
+		t.Cleanup(func() { close(release) })
+		_, port, err := net.SplitHostPort(hanging.Listener.Addr().String())
+		require.NoError(t, err)
+		p, err := strconv.Atoi(port)
+		require.NoError(t, err)
+
+		rbg := buildRegistrationRBG(int32(p))
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+			buildRegistrationPod("prefill-0", "prefill", "10.0.0.1", readyTime),
+		)
+		r := NewRegistrationReconciler(c)
+		r.timeout = 50 * time.Millisecond
+
+		start := time.Now()
+		statuses, err := r.Reconcile(context.TODO(), rbg)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), registrationRequestTimeout)
+		require.Len(t, statuses, 1)
+		assert.Equal(t, []workloadsv1alpha1.RegisteredBackend{prefill0}, statuses[0].Pending)
+	})
+
+	t.Run("Keep the registrations if the registration is invalid", func(t *testing.T) {
+		router := newFakeRouter(t)
+		rbg := buildRegistrationRBG(router.port(t))
+		rbg.Spec.Roles[0].Registration.Backends = []workloadsv1alpha1.RegistrationBackend{{Role: "decode"}}
+		rbg.Status.Registrations = []workloadsv1alpha1.RegistrationStatus{{
+			Role: "router", Instance: "router-0", Backends: []workloadsv1alpha1.RegisteredBackend{decode0},
+		}}
+		c := newRegistrationClient(scheme, rbg,
+			buildRegistrationPod("router-0", "router", "127.0.0.1", readyTime),
+		)
+
+		statuses, err := NewRegistrationReconciler(c).Reconcile(context.TODO(), rbg)
+		assert.ErrorContains(t, err, "no service port")
+		assert.Empty(t, router.takeRequests())
+		assert.Equal(t, rbg.Status.Registrations, statuses)
+	})
+}
@@ -0,0 +1,202 @@
+package reconciler
+
+import (
+	"context"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+// ServiceReconciler reconciles the Services defined by role.service. The headless Services of StatefulSet roles
+// are reconciled by StatefulSetReconciler, the ones of LeaderWorkerSet roles are managed by LeaderWorkerSet.
+type ServiceReconciler struct {
+	client client.Client
+}
+
+func NewServiceReconciler(client client.Client) *ServiceReconciler {
+	return &ServiceReconciler{client: client}
+}
+
+// Reconcile creates or updates the Services of the roles of rbg, and deletes the Services created by rbg which
+// are no longer desired.
+func (r *ServiceReconciler) Reconcile(ctx context.Context, rbg *workloadsv1alpha1.RoleBasedGroup) error {
+	errs := make([]error, 0)
+	desiredNames := make(map[string]struct{})
+	for i := range rbg.Spec.Roles {
+		svc := constructRoleService(rbg, &rbg.Spec.Roles[i])
+		if svc == nil {
+			continue
+		}
+		desiredNames[svc.Name] = struct{}{}
+		if err := r.createOrUpdate(ctx, svc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	svcList := &corev1.ServiceList{}
+	if err := r.client.List(ctx, svcList, client.InNamespace(rbg.Namespace),
+		client.MatchingLabels{workloadsv1alpha1.SetNameLabelKey: rbg.Name},
+	); err != nil {
+		errs = append(errs, err)
+		return utilerrors.NewAggregate(errs)
+	}
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		// the headless services of sts are owned by the sts and not controlled by rbg
+		if _, ok := desiredNames[svc.Name]; ok || !metav1.IsControlledBy(svc, rbg) {
+			continue
+		}
+		log.FromContext(ctx).Info("delete svc", "svc", svc.Name)
+		if err := r.client.Delete(ctx, svc); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (r *ServiceReconciler) createOrUpdate(ctx context.Context, svc *corev1.Service) error {
+	logger := log.FromContext(ctx)
+
+	oldSvc := &corev1.Service{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, oldSvc)
+	if apierrors.IsNotFound(err) {
+		logger.Info("create svc", "svc", svc.Name)
+		return r.client.Create(ctx, svc)
+	} else if err != nil {
+		return err
+	}
+
+	newSvc := mergeService(oldSvc, svc)
+	if equality.Semantic.DeepEqual(oldSvc, newSvc) {
+		logger.V(1).Info("svc equal, skip reconcile", "svc", svc.Name)
+		return nil
+	}
+	logger.Info("update svc", "svc", svc.Name)
+	return r.client.Update(ctx, newSvc)
+}
+
+// constructRoleService returns the Service of role managed by ServiceReconciler, or nil if there is none.
+func constructRoleService(rbg *workloadsv1alpha1.RoleBasedGroup, role *workloadsv1alpha1.RoleSpec) *corev1.Service {
+	if role.Service == nil {
+		return nil
+	}
+	headless := role.Service.Type == "" || role.Service.Type == workloadsv1alpha1.HeadlessServiceType
+	if headless && role.Workload.String() != workloadsv1alpha1.DeploymentWorkloadType {
+		return nil
+	}
+
+	labels := rbg.GetCommonLabelsFromRole(role)
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        rbg.GetServiceName(role),
+			Namespace:   rbg.Namespace,
+			Labels:      labels,
+			Annotations: maps.Clone(role.Service.Annotations),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:              labels,
+			Ports:                 defaultServicePorts(role.ServicePorts),
+			SessionAffinity:       role.Service.SessionAffinity,
+			SessionAffinityConfig: role.Service.SessionAffinityConfig.DeepCopy(),
+		},
+	}
+	if headless {
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+		svc.Spec.PublishNotReadyAddresses = true
+	} else {
+		svc.Spec.Type = corev1.ServiceType(role.Service.Type)
+	}
+	defaultSessionAffinity(&svc.Spec)
+	return svc
+}
+
+// mergeService returns oldSvc updated with the fields of svc managed by rbg. The fields allocated by the
+// apiserver, e.g. cluster IP and node ports, and the labels and annotations added by others are kept.
+func mergeService(oldSvc, svc *corev1.Service) *corev1.Service {
+	newSvc := oldSvc.DeepCopy()
+	if newSvc.Labels == nil {
+		newSvc.Labels = map[string]string{}
+	}
+	maps.Copy(newSvc.Labels, svc.Labels)
+	if len(svc.Annotations) > 0 {
+		if newSvc.Annotations == nil {
+			newSvc.Annotations = map[string]string{}
+		}
+		maps.Copy(newSvc.Annotations, svc.Annotations)
+	}
+	newSvc.OwnerReferences = svc.OwnerReferences
+
+	newSvc.Spec.Type = svc.Spec.Type
+	newSvc.Spec.Selector = svc.Spec.Selector
+	newSvc.Spec.SessionAffinity = svc.Spec.SessionAffinity
+	newSvc.Spec.SessionAffinityConfig = svc.Spec.SessionAffinityConfig
+	newSvc.Spec.PublishNotReadyAddresses = svc.Spec.PublishNotReadyAddresses
+
+	hasNodePorts := svc.Spec.Type == corev1.ServiceTypeNodePort || svc.Spec.Type == corev1.ServiceTypeLoadBalancer
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		if hasNodePorts && port.NodePort == 0 {
+			for _, oldPort := range oldSvc.Spec.Ports {
+				if oldPort.Port == port.Port && oldPort.Protocol == port.Protocol {
+					port.NodePort = oldPort.NodePort
+				}
+			}
+		}
+		ports = append(ports, port)
+	}
+	newSvc.Spec.Ports = ports
+
+	// these fields are only allowed for the services exposed on nodes
+	if !hasNodePorts {
+		newSvc.Spec.ExternalTrafficPolicy = ""
+		newSvc.Spec.HealthCheckNodePort = 0
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		newSvc.Spec.AllocateLoadBalancerNodePorts = nil
+		newSvc.Spec.LoadBalancerClass = nil
+	}
+	return newSvc
+}
+
+// defaultServicePorts sets the protocol and the target port of ports as the apiserver does, so that the desired
+// ports are comparable with the existing ones.
+func defaultServicePorts(ports []corev1.ServicePort) []corev1.ServicePort {
+	result := make([]corev1.ServicePort, 0, len(ports))
+	for _, port := range ports {
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+			port.TargetPort = intstr.FromInt32(port.Port)
+		}
+		result = append(result, port)
+	}
+	return result
+}
+
+func defaultSessionAffinity(spec *corev1.ServiceSpec) {
+	if spec.SessionAffinity == "" {
+		spec.SessionAffinity = corev1.ServiceAffinityNone
+	}
+	if spec.SessionAffinity == corev1.ServiceAffinityClientIP && spec.SessionAffinityConfig == nil {
+		spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{
+				TimeoutSeconds: ptr.To(int32(corev1.DefaultClientIPServiceAffinitySeconds)),
+			},
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workloadsv1alpha1 "sigs.k8s.io/rbgs/api/workloads/v1alpha1"
+)
+
+func TestServiceReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = workloadsv1alpha1.AddToScheme(scheme)
+
+	deployment := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "Deployment"}
+	statefulSet := workloadsv1alpha1.WorkloadSpec{APIVersion: "apps/v1", Kind: "StatefulSet"}
+	ports := []corev1.ServicePort{{Name: "http", Port: 8000}}
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default", UID: "rbg-uid"},
+		Spec: workloadsv1alpha1.RoleBasedGroupSpec{
+			Roles: []workloadsv1alpha1.RoleSpec{
+				{
+					Name:         "router",
+					Workload:     deployment,
+					ServicePorts: ports,
+					Service: &workloadsv1alpha1.RoleService{
+						Type:            workloadsv1alpha1.LoadBalancerServiceType,
+						Annotations:     map[string]string{"lb.example.com/internal": "true"},
+						SessionAffinity: corev1.ServiceAffinityClientIP,
+					},
+				},
+				{
+					Name:         "gateway",
+					Workload:     deployment,
+					ServicePorts: ports,
+					Service:      &workloadsv1alpha1.RoleService{Type: workloadsv1alpha1.HeadlessServiceType},
+				},
+				{
+					// the headless service of sts is reconciled by StatefulSetReconciler
+					Name:         "prefill",
+					Workload:     statefulSet,
+					ServicePorts: ports,
+					Service:      &workloadsv1alpha1.RoleService{Type: workloadsv1alpha1.HeadlessServiceType},
+				},
+				{
+					Name:         "decode",
+					Workload:     statefulSet,
+					ServicePorts: ports,
+					Service:      &workloadsv1alpha1.RoleService{Type: workloadsv1alpha1.ClusterIPServiceType},
+				},
+			},
+		},
+	}
+	controllerRef := []metav1.OwnerReference{
+		*metav1.NewControllerRef(rbg, workloadsv1alpha1.GroupVersion.WithKind("RoleBasedGroup")),
+	}
+
+	// the router service was a NodePort service, the service of the removed worker role is orphaned
+	oldRouter := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-router-svc", Namespace: "default", OwnerReferences: controllerRef,
+			Labels:      map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg"},
+			Annotations: map[string]string{"added-by": "cloud-provider"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.1",
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 8000, Protocol: corev1.ProtocolTCP, NodePort: 30080}},
+		},
+	}
+	oldWorker := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-worker-svc", Namespace: "default", OwnerReferences: controllerRef,
+			Labels: map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg"},
+		},
+	}
+	// owned by the sts
+	stsHeadless := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-rbg-prefill", Namespace: "default",
+			Labels: map[string]string{workloadsv1alpha1.SetNameLabelKey: "test-rbg"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(rbg, oldRouter, oldWorker, stsHeadless).Build()
+	assert.NoError(t, NewServiceReconciler(fakeClient).Reconcile(context.TODO(), rbg))
+
+	getSvc := func(name string) (*corev1.Service, error) {
+		svc := &corev1.Service{}
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, svc)
+		return svc, err
+	}
+	selector := func(role string) map[string]string {
+		return map[string]string{
+			workloadsv1alpha1.SetNameLabelKey: "test-rbg",
+			workloadsv1alpha1.SetRoleLabelKey: role,
+		}
+	}
+
+	router, err := getSvc("test-rbg-router-svc")
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, router.Spec.Type)
+	assert.Equal(t, "10.0.0.1", router.Spec.ClusterIP)
+	assert.Equal(t, selector("router"), router.Spec.Selector)
+	assert.Equal(t, []corev1.ServicePort{{
+		Name: "http", Port: 8000, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromInt32(8000), NodePort: 30080,
+	}}, router.Spec.Ports)
+	assert.Equal(t, corev1.ServiceAffinityClientIP, router.Spec.SessionAffinity)
+	assert.Equal(t, ptr.To(int32(corev1.DefaultClientIPServiceAffinitySeconds)),
+		router.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds)
+	assert.Equal(t, "true", router.Annotations["lb.example.com/internal"])
+	assert.Equal(t, "cloud-provider", router.Annotations["added-by"])
+
+	gateway, err := getSvc("test-rbg-gateway")
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ClusterIPNone, gateway.Spec.ClusterIP)
+	assert.True(t, gateway.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, selector("gateway"), gateway.Spec.Selector)
+	assert.True(t, metav1.IsControlledBy(gateway, rbg))
+
+	decode, err := getSvc("test-rbg-decode-svc")
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, decode.Spec.Type)
+	assert.Equal(t, selector("decode"), decode.Spec.Selector)
+
+	_, err = getSvc("test-rbg-worker-svc")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	prefill, err := getSvc("test-rbg-prefill")
+	assert.NoError(t, err)
+	assert.Nil(t, prefill.Spec.Selector)
+}
+
+func TestMergeService_ClearNodePortFields(t *testing.T) {
+	oldSvc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:                          corev1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy:         corev1.ServiceExternalTrafficPolicyCluster,
+			Ports:                         []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP, NodePort: 30080}},
+			AllocateLoadBalancerNodePorts: ptr.To(true),
+		},
+	}
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+
+	newSvc := mergeService(oldSvc, svc)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, newSvc.Spec.Type)
+	assert.Equal(t, int32(0), newSvc.Spec.Ports[0].NodePort)
+	assert.Empty(t, newSvc.Spec.ExternalTrafficPolicy)
+	assert.Nil(t, newSvc.Spec.AllocateLoadBalancerNodePorts)
+}
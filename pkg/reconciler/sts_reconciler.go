@@ -11,6 +11,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -372,6 +373,7 @@ func (r *StatefulSetReconciler) reconcileHeadlessService(
 	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj, newSvc); err != nil {
 		return fmt.Errorf("convert svcApplyConfig to svc error: %s", err.Error())
 	}
+	defaultSessionAffinity(&newSvc.Spec)
 
 	oldSvc := &corev1.Service{}
 	err = r.client.Get(ctx, types.NamespacedName{Name: rbg.GetWorkloadName(role), Namespace: rbg.Namespace}, oldSvc)
@@ -452,15 +454,44 @@ func (r *StatefulSetReconciler) constructServiceApplyConfiguration(
 		workloadsv1alpha1.SetNameLabelKey: rbg.Name,
 		workloadsv1alpha1.SetRoleLabelKey: role.Name,
 	}
+	serviceSpecConfig := coreapplyv1.ServiceSpec().
+		WithClusterIP("None").
+		WithSelector(selectMap).
+		WithPublishNotReadyAddresses(true)
+	annotations := rbg.GetCommonAnnotationsFromRole(role)
+
+	// the headless service is customized by role.service of type Headless
+	if role.Service != nil && rbg.GetServiceName(role) == rbg.GetWorkloadName(role) {
+		for _, port := range defaultServicePorts(role.ServicePorts) {
+			portConfig := coreapplyv1.ServicePort().
+				WithName(port.Name).
+				WithProtocol(port.Protocol).
+				WithPort(port.Port).
+				WithTargetPort(port.TargetPort)
+			if port.AppProtocol != nil {
+				portConfig = portConfig.WithAppProtocol(*port.AppProtocol)
+			}
+			serviceSpecConfig = serviceSpecConfig.WithPorts(portConfig)
+		}
+		if role.Service.SessionAffinity != "" {
+			serviceSpecConfig = serviceSpecConfig.WithSessionAffinity(role.Service.SessionAffinity)
+		}
+		if config := role.Service.SessionAffinityConfig; config != nil && config.ClientIP != nil {
+			clientIPConfig := coreapplyv1.ClientIPConfig()
+			if config.ClientIP.TimeoutSeconds != nil {
+				clientIPConfig = clientIPConfig.WithTimeoutSeconds(*config.ClientIP.TimeoutSeconds)
+			}
+			serviceSpecConfig = serviceSpecConfig.WithSessionAffinityConfig(
+				coreapplyv1.SessionAffinityConfig().WithClientIP(clientIPConfig),
+			)
+		}
+		maps.Copy(annotations, role.Service.Annotations)
+	}
+
 	serviceConfig := coreapplyv1.Service(rbg.GetWorkloadName(role), rbg.Namespace).
-		WithSpec(
-			coreapplyv1.ServiceSpec().
-				WithClusterIP("None").
-				WithSelector(selectMap).
-				WithPublishNotReadyAddresses(true),
-		).
+		WithSpec(serviceSpecConfig).
 		WithLabels(rbg.GetCommonLabelsFromRole(role)).
-		WithAnnotations(rbg.GetCommonAnnotationsFromRole(role)).
+		WithAnnotations(annotations).
 		WithOwnerReferences(
 			metaapplyv1.OwnerReference().
 				WithAPIVersion(sts.APIVersion).
@@ -487,13 +518,19 @@ func (r *StatefulSetReconciler) ConstructRoleStatus(
 
 	currentReplicas := *sts.Spec.Replicas
 	currentReady := sts.Status.ReadyReplicas
+	// the status of the sts is stale until the sts controller observes its latest generation
+	var currentUpdated int32
+	if sts.Status.ObservedGeneration == sts.Generation {
+		currentUpdated = sts.Status.UpdatedReplicas
+	}
 	status, found := rbg.GetRoleStatus(role.Name)
-	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady {
-		status = workloadsv1alpha1.RoleStatus{
-			Name:          role.Name,
-			Replicas:      currentReplicas,
-			ReadyReplicas: currentReady,
-		}
+	if !found || status.Replicas != currentReplicas || status.ReadyReplicas != currentReady ||
+		status.UpdatedReplicas != currentUpdated {
+		// keep the restart records of the role
+		status.Name = role.Name
+		status.Replicas = currentReplicas
+		status.ReadyReplicas = currentReady
+		status.UpdatedReplicas = currentUpdated
 		updateStatus = true
 	}
 	return status, updateStatus, nil
@@ -687,6 +724,17 @@ func SemanticallyEqualService(svc1, svc2 *corev1.Service) (bool, error) {
 		return false, fmt.Errorf("selector not equal, old: %v, new: %v", svc1.Spec.Selector, svc2.Spec.Selector)
 	}
 
+	if !equality.Semantic.DeepEqual(svc1.Spec.Ports, svc2.Spec.Ports) {
+		return false, fmt.Errorf("ports not equal, old: %v, new: %v", svc1.Spec.Ports, svc2.Spec.Ports)
+	}
+
+	if svc1.Spec.SessionAffinity != svc2.Spec.SessionAffinity ||
+		!equality.Semantic.DeepEqual(svc1.Spec.SessionAffinityConfig, svc2.Spec.SessionAffinityConfig) {
+		return false, fmt.Errorf("session affinity not equal, old: %v %v, new: %v %v",
+			svc1.Spec.SessionAffinity, svc1.Spec.SessionAffinityConfig,
+			svc2.Spec.SessionAffinity, svc2.Spec.SessionAffinityConfig)
+	}
+
 	return true, nil
 }
 
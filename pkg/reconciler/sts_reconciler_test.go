@@ -5,6 +5,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
@@ -461,3 +463,55 @@ func TestStatefulSetReconciler_rollingUpdateParameters(t *testing.T) {
 		})
 	}
 }
+
+func TestStatefulSetReconciler_constructServiceApplyConfiguration(t *testing.T) {
+	rbg := &workloadsv1alpha1.RoleBasedGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-rbg", Namespace: "default"},
+	}
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "test-rbg-prefill", UID: "sts-uid"}}
+	r := NewStatefulSetReconciler(runtime.NewScheme(), nil)
+
+	tests := []struct {
+		name              string
+		service           *workloadsv1alpha1.RoleService
+		expectPorts       int
+		expectAnnotation  bool
+		expectAffinitySet bool
+	}{
+		{
+			name: "Plain headless service",
+		},
+		{
+			name: "Customized headless service",
+			service: &workloadsv1alpha1.RoleService{
+				Type:            workloadsv1alpha1.HeadlessServiceType,
+				Annotations:     map[string]string{"foo": "bar"},
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+			},
+			expectPorts:       1,
+			expectAnnotation:  true,
+			expectAffinitySet: true,
+		},
+		{
+			name:    "Headless service is not customized by a ClusterIP service",
+			service: &workloadsv1alpha1.RoleService{Type: workloadsv1alpha1.ClusterIPServiceType},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role := &workloadsv1alpha1.RoleSpec{
+				Name:         "prefill",
+				Replicas:     ptr.To(int32(1)),
+				ServicePorts: []corev1.ServicePort{{Name: "http", Port: 8000}},
+				Service:      tt.service,
+			}
+			svc := r.constructServiceApplyConfiguration(context.TODO(), rbg, role, sts)
+			assert.Equal(t, "None", *svc.Spec.ClusterIP)
+			assert.Len(t, svc.Spec.Ports, tt.expectPorts)
+			assert.Equal(t, tt.expectAnnotation, svc.Annotations["foo"] == "bar")
+			assert.Equal(t, tt.expectAffinitySet, svc.Spec.SessionAffinity != nil)
+			assert.Equal(t, "1", svc.Annotations[workloadsv1alpha1.RoleSizeAnnotationKey])
+		})
+	}
+}
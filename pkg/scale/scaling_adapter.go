@@ -33,3 +33,26 @@ func IsScalingAdapterEnable(roleSpec *workloadsv1alpha.RoleSpec) bool {
 	}
 	return roleSpec.ScalingAdapter.Enable
 }
+
+// GenerateSetScalingAdapterName returns the name of the scaling adapter of a rbgset. The dot keeps it apart from the
+// names <rbg>-<role> of the scaling adapters of roles, which never contain dots as they also name Services.
+func GenerateSetScalingAdapterName(rbgSetName string) string {
+	return rbgSetName + ".scaling-adapter"
+}
+
+func IsScalingAdapterManagedByRBGSet(
+	scalingAdapter *workloadsv1alpha.RoleBasedGroupScalingAdapter,
+	rbgset *workloadsv1alpha.RoleBasedGroupSet,
+) bool {
+	if scalingAdapter == nil || rbgset == nil {
+		return false
+	}
+
+	for _, owner := range scalingAdapter.OwnerReferences {
+		if owner.UID == rbgset.UID {
+			return true
+		}
+	}
+
+	return false
+}
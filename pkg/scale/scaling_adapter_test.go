@@ -205,3 +205,54 @@ func TestIsScalingAdapterEnable(t *testing.T) {
 		})
 	}
 }
+
+func TestIsScalingAdapterManagedByRBGSet(t *testing.T) {
+	rbgSet := &workloadsv1alpha.RoleBasedGroupSet{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: types.UID("test-rbgset-uid"),
+		},
+	}
+
+	tests := []struct {
+		name           string
+		scalingAdapter *workloadsv1alpha.RoleBasedGroupScalingAdapter
+		rbgSet         *workloadsv1alpha.RoleBasedGroupSet
+		expected       bool
+	}{
+		{
+			name:           "RBGSet is nil",
+			scalingAdapter: &workloadsv1alpha.RoleBasedGroupScalingAdapter{},
+			rbgSet:         nil,
+			expected:       false,
+		},
+		{
+			name: "Found matching OwnerReference",
+			scalingAdapter: &workloadsv1alpha.RoleBasedGroupScalingAdapter{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{UID: rbgSet.UID}},
+				},
+			},
+			rbgSet:   rbgSet,
+			expected: true,
+		},
+		{
+			name: "No matching OwnerReference",
+			scalingAdapter: &workloadsv1alpha.RoleBasedGroupScalingAdapter{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{{UID: types.UID("other-uid")}},
+				},
+			},
+			rbgSet:   rbgSet,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := IsScalingAdapterManagedByRBGSet(tt.scalingAdapter, tt.rbgSet)
+			if result != tt.expected {
+				t.Errorf("IsScalingAdapterManagedByRBGSet() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,30 +26,52 @@ func NewPodGroupScheduler(client client.Client) *PodGroupScheduler {
 
 func (r *PodGroupScheduler) Reconcile(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
 	if rbg.EnableGangScheduling() {
-		return r.createOrUpdatePodGroup(ctx, rbg)
+		return r.createOrUpdatePodGroup(ctx, rbg, rbg.GroupVersionKind(), int32(rbg.GetGroupSize()),
+			rbg.Spec.PodGroupPolicy.KubeScheduling.ScheduleTimeoutSeconds)
 	} else {
 		return r.deletePodGroup(ctx, rbg)
 	}
 
 }
 
-func (r *PodGroupScheduler) createOrUpdatePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+// ReconcileRBGSet reconciles the PodGroup spanning the pods of all rbgs of rbgset, minMember is the total
+// group size of the rbgs.
+func (r *PodGroupScheduler) ReconcileRBGSet(
+	ctx context.Context, rbgset *workloadsv1alpha.RoleBasedGroupSet, minMember int32,
+) error {
+	policy := rbgset.Spec.PodGroupPolicy
+	if policy != nil && policy.KubeScheduling != nil {
+		return r.createOrUpdatePodGroup(ctx, rbgset, workloadsv1alpha.GroupVersion.WithKind("RoleBasedGroupSet"),
+			minMember, policy.KubeScheduling.ScheduleTimeoutSeconds)
+	}
+	err := r.deletePodGroup(ctx, rbgset)
+	// nothing to delete if the PodGroup CRD is not installed
+	if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *PodGroupScheduler) createOrUpdatePodGroup(
+	ctx context.Context, owner client.Object, ownerGVK schema.GroupVersionKind, minMember int32,
+	scheduleTimeoutSeconds *int32,
+) error {
 	logger := log.FromContext(ctx)
 	podGroup := &schedv1alpha1.PodGroup{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      rbg.Name,
-			Namespace: rbg.Namespace,
+			Name:      owner.GetName(),
+			Namespace: owner.GetNamespace(),
 			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(rbg, rbg.GroupVersionKind()),
+				*metav1.NewControllerRef(owner, ownerGVK),
 			},
 		},
 		Spec: schedv1alpha1.PodGroupSpec{
-			MinMember:              int32(rbg.GetGroupSize()),
-			ScheduleTimeoutSeconds: rbg.Spec.PodGroupPolicy.KubeScheduling.ScheduleTimeoutSeconds,
+			MinMember:              minMember,
+			ScheduleTimeoutSeconds: scheduleTimeoutSeconds,
 		},
 	}
 
-	err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup)
+	err := r.client.Get(ctx, types.NamespacedName{Name: owner.GetName(), Namespace: owner.GetNamespace()}, podGroup)
 	if err != nil && !apierrors.IsNotFound(err) {
 		logger.Error(err, "get pod group error")
 		return err
@@ -60,12 +85,12 @@ func (r *PodGroupScheduler) createOrUpdatePodGroup(ctx context.Context, rbg *wor
 		return err
 	}
 
-	if podGroup.Spec.MinMember != int32(rbg.GetGroupSize()) {
+	if podGroup.Spec.MinMember != minMember {
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+			if err := r.client.Get(ctx, types.NamespacedName{Name: owner.GetName(), Namespace: owner.GetNamespace()}, podGroup); err != nil {
 				return err
 			}
-			podGroup.Spec.MinMember = int32(rbg.GetGroupSize())
+			podGroup.Spec.MinMember = minMember
 			updateErr := r.client.Update(ctx, podGroup)
 			return updateErr
 		})
@@ -78,14 +103,17 @@ func (r *PodGroupScheduler) createOrUpdatePodGroup(ctx context.Context, rbg *wor
 	return nil
 }
 
-func (r *PodGroupScheduler) deletePodGroup(ctx context.Context, rbg *workloadsv1alpha.RoleBasedGroup) error {
+func (r *PodGroupScheduler) deletePodGroup(ctx context.Context, owner client.Object) error {
 	podGroup := &schedv1alpha1.PodGroup{}
-	if err := r.client.Get(ctx, types.NamespacedName{Name: rbg.Name, Namespace: rbg.Namespace}, podGroup); err != nil {
+	if err := r.client.Get(ctx, types.NamespacedName{Name: owner.GetName(), Namespace: owner.GetNamespace()}, podGroup); err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil
 		}
 		return err
 	}
+	if !metav1.IsControlledBy(podGroup, owner) {
+		return nil
+	}
 
 	return r.client.Delete(ctx, podGroup)
 }
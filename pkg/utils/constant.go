@@ -7,6 +7,12 @@ const (
 	// LwsCrdName is LWS CRD name
 	LwsCrdName = "leaderworkersets.leaderworkerset.x-k8s.io"
 
+	// HTTPRouteCrdName is Gateway API HTTPRoute CRD name
+	HTTPRouteCrdName = "httproutes.gateway.networking.k8s.io"
+
+	// InferencePoolCrdName is Gateway API Inference Extension InferencePool CRD name
+	InferencePoolCrdName = "inferencepools.inference.networking.x-k8s.io"
+
 	// RbgCRDName is rbg crd name
 	RbgCRDName = "rolebasedgroups.workloads.x-k8s.io"
 
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeReady returns true when the Ready condition of the node is true.
+func NodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// NodeNotReadySince returns the time when the node became NotReady. The second return value is false
+// when the node is ready or has not reported its Ready condition yet.
+func NodeNotReadySince(node *corev1.Node) (time.Time, bool) {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			if cond.Status == corev1.ConditionTrue {
+				return time.Time{}, false
+			}
+			return cond.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -68,3 +68,8 @@ func ContainerRestarted(pod *corev1.Pod) bool {
 func PodDeleted(pod *corev1.Pod) bool {
 	return pod.DeletionTimestamp != nil
 }
+
+// PodEvicted return true when the pod is evicted by kubelet, e.g. under node pressure
+func PodEvicted(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted"
+}
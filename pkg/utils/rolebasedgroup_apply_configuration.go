@@ -56,14 +56,21 @@ func (b *RbgApplyConfiguration) ensureObjectMetaApplyConfigurationExists() {
 }
 
 type RbgStatusApplyConfiguration struct {
-	Conditions   []v1.Condition        `json:"conditions,omitempty"`
-	RoleStatuses []v1alpha1.RoleStatus `json:"roleStatuses,omitempty"`
+	ObservedGeneration *int64                        `json:"observedGeneration,omitempty"`
+	Conditions         []v1.Condition                `json:"conditions,omitempty"`
+	RoleStatuses       []v1alpha1.RoleStatus         `json:"roleStatuses,omitempty"`
+	Registrations      []v1alpha1.RegistrationStatus `json:"registrations,omitempty"`
 }
 
 func RbgStatus() *RbgStatusApplyConfiguration {
 	return &RbgStatusApplyConfiguration{}
 }
 
+func (b *RbgStatusApplyConfiguration) WithObservedGeneration(value int64) *RbgStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
 func (b *RbgStatusApplyConfiguration) WithConditions(conditions []v1.Condition) *RbgStatusApplyConfiguration {
 	b.Conditions = conditions
 	return b
@@ -75,3 +82,10 @@ func (b *RbgStatusApplyConfiguration) WithRoleStatuses(
 	b.RoleStatuses = roleStatuses
 	return b
 }
+
+func (b *RbgStatusApplyConfiguration) WithRegistrations(
+	registrations []v1alpha1.RegistrationStatus,
+) *RbgStatusApplyConfiguration {
+	b.Registrations = registrations
+	return b
+}
@@ -121,7 +121,8 @@ func FilterSystemLabels(labels map[string]string) map[string]string {
 	for k, v := range labels {
 
 		if !strings.HasPrefix(k, "app.kubernetes.io/") &&
-			!strings.HasPrefix(k, "rolebasedgroup.workloads.x-k8s.io/") {
+			!strings.HasPrefix(k, "rolebasedgroup.workloads.x-k8s.io/") &&
+			!strings.HasPrefix(k, "rolebasedgroupset.workloads.x-k8s.io/") {
 			filtered[k] = v
 		}
 	}
@@ -33,6 +33,11 @@ func (podWrapper *PodWrapper) WithLabels(labels map[string]string) *PodWrapper {
 	return podWrapper
 }
 
+func (podWrapper *PodWrapper) WithAnnotations(annotations map[string]string) *PodWrapper {
+	podWrapper.Annotations = annotations
+	return podWrapper
+}
+
 func (podWrapper *PodWrapper) WithReadyCondition(ready bool) *PodWrapper {
 	var conditionStatus corev1.ConditionStatus
 	if ready {
@@ -55,6 +55,11 @@ func (roleWrapper *RoleWrapper) WithDependencies(dependencies []string) *RoleWra
 	return roleWrapper
 }
 
+func (roleWrapper *RoleWrapper) WithDependsOn(dependsOn []workloadsv1alpha.Dependency) *RoleWrapper {
+	roleWrapper.DependsOn = dependsOn
+	return roleWrapper
+}
+
 func (roleWrapper *RoleWrapper) WithRollingUpdate(rollingUpdate workloadsv1alpha.RollingUpdate) *RoleWrapper {
 	roleWrapper.RolloutStrategy = &workloadsv1alpha.RolloutStrategy{
 		Type:          workloadsv1alpha.RollingUpdateStrategyType,
@@ -68,6 +73,16 @@ func (roleWrapper *RoleWrapper) WithRestartPolicy(restartPolicy workloadsv1alpha
 	return roleWrapper
 }
 
+func (roleWrapper *RoleWrapper) WithRestartBackoff(backoff workloadsv1alpha.RestartBackoffPolicy) *RoleWrapper {
+	roleWrapper.RestartBackoff = &backoff
+	return roleWrapper
+}
+
+func (roleWrapper *RoleWrapper) WithInstanceSize(size int32) *RoleWrapper {
+	roleWrapper.InstanceSize = ptr.To(size)
+	return roleWrapper
+}
+
 func (roleWrapper *RoleWrapper) WithWorkload(workloadType string) *RoleWrapper {
 	switch workloadType {
 	case workloadsv1alpha.DeploymentWorkloadType: